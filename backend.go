@@ -3,8 +3,10 @@ package cliui
 import (
 	"io"
 	"log"
+	"strings"
 
 	"github.com/ershixiongTQL/cli-ui/completer"
+	"github.com/ershixiongTQL/cli-ui/interfaces"
 	"github.com/ershixiongTQL/cli-ui/router"
 )
 
@@ -12,16 +14,79 @@ type uiBackend struct {
 	completer completer.Completer
 }
 
-func (be *uiBackend) Completer(input string) (completions []string) {
-	return be.completer.GetCompletes(input)
+func (be *uiBackend) Completer(input string) (completions []interfaces.Completion, replace int) {
+
+	candidates, replace := be.completer.Complete(input)
+
+	kinds := map[completer.CandidateKind]interfaces.COMPLETION_KIND{
+		completer.CandidateKeyword:  interfaces.COMPLETION_KIND_KEYWORD,
+		completer.CandidateValue:    interfaces.COMPLETION_KIND_VALUE,
+		completer.CandidateArgument: interfaces.COMPLETION_KIND_ARGUMENT,
+	}
+
+	for _, c := range candidates {
+		completions = append(completions, interfaces.Completion{Text: c.Text, Display: c.Value, Description: c.Description, Kind: kinds[c.Kind]})
+	}
+
+	return
+}
+
+func (be *uiBackend) Classify(input string) (tokens []interfaces.Token) {
+
+	classes := map[completer.TokenClass]interfaces.TOKEN_CLASS{
+		completer.TokenKeyword:   interfaces.TOKEN_CLASS_KEYWORD,
+		completer.TokenValue:     interfaces.TOKEN_CLASS_VALUE,
+		completer.TokenArgument:  interfaces.TOKEN_CLASS_ARGUMENT,
+		completer.TokenInvalid:   interfaces.TOKEN_CLASS_INVALID,
+		completer.TokenUnchecked: interfaces.TOKEN_CLASS_UNCHECKED,
+	}
+
+	for _, t := range be.completer.Classify(input) {
+		tokens = append(tokens, interfaces.Token{Start: t.Start, End: t.End, Class: classes[t.Class]})
+	}
+
+	return
 }
 
 func (be *uiBackend) Helps(input string) (help string) {
 	return be.completer.GetHelps(input)
 }
 
+func (be *uiBackend) Executable(input string) bool {
+	return be.completer.Executable(input)
+}
+
 func (be *uiBackend) CommandHandler(command string, resultIO io.StringWriter) error {
-	return router.Mux(command, resultIO)
+	command, body, _, _ := completer.SplitHeredoc(command)
+
+	if err := be.completer.Validate(command); err != nil {
+		//a line the schema does not take may still have a handler, e.g. "show version" next to the
+		//schema command "show interface", it is routed as it is
+		if routed := strings.TrimLeft(command, " \t"); router.Match(routed) {
+			be.completer.Record(routed)
+			return router.MuxWithBody(routed, body, resultIO)
+		}
+		return err
+	}
+
+	//a validated line is not ambiguous
+	expanded, err := be.completer.Expand(command)
+	if err != nil {
+		return err
+	}
+
+	be.completer.Record(expanded)
+
+	if handled, err := be.completer.RunBuiltin(expanded, resultIO); handled {
+		return err
+	}
+
+	if be.completer.RunRegistered(expanded, body, resultIO) {
+		return nil
+	}
+
+	//the patterns of the handlers are anchored at the start of the line
+	return router.MuxWithBody(strings.TrimLeft(expanded, " \t"), body, resultIO)
 }
 
 func (be *uiBackend) UserAuth(username string, passwd string) bool {
@@ -29,10 +94,10 @@ func (be *uiBackend) UserAuth(username string, passwd string) bool {
 	return true
 }
 
-func backendPrepare(configFilePath string) (be *uiBackend) {
+func backendPrepare(setupSchema func(c *completer.Completer) error) (be *uiBackend) {
 	be = new(uiBackend)
 
-	if err := be.completer.Setup(configFilePath); err != nil {
+	if err := setupSchema(&be.completer); err != nil {
 		log.Println(err.Error())
 		return nil
 	}
@@ -0,0 +1,122 @@
+package cliui
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+	"github.com/ershixiongTQL/cli-ui/router"
+)
+
+const backendSchema = `{"commands": [
+ {"name": "show interface", "prefix": "show interface", "param": [{"name": "name", "type": "plain"}]},
+ {"name": "shutdown", "prefix": "shutdown"}
+]}`
+
+//the lines the router handlers of the tests are run with, and the bodies
+var (
+	routeOnce    sync.Once
+	routed       []string
+	routedBodies []string
+)
+
+func newTestBackend(t *testing.T) *uiBackend {
+	t.Helper()
+
+	//the router keeps its units for the process, they are registered once
+	routeOnce.Do(func() {
+		for _, pattern := range []string{`^show interface(?:\s|$)`, `^shutdown$`, `^show version$`} {
+			router.UnitRegisterDefault(pattern, pattern, func(input router.Input, w io.StringWriter) {
+				routed = append(routed, input.GetRaw())
+				routedBodies = append(routedBodies, input.GetBody())
+			})
+		}
+	})
+
+	routed, routedBodies = nil, nil
+
+	be := backendPrepare(func(c *completer.Completer) error { return c.SetupBytes([]byte(backendSchema), completer.FormatJSON) })
+	if be == nil {
+		t.Fatal("backend not prepared")
+	}
+
+	return be
+}
+
+func TestCommandHandlerRoutes(t *testing.T) {
+
+	be := newTestBackend(t)
+
+	for _, line := range []string{"show interface eth0", "  sh int eth0", "\tshu"} {
+		if err := be.CommandHandler(line, new(strings.Builder)); err != nil {
+			t.Errorf("%q: %v", line, err)
+		}
+	}
+
+	if expected := []string{"show interface eth0", "show interface eth0", "shutdown"}; !reflect.DeepEqual(routed, expected) {
+		t.Errorf("routed %q, expected %q", routed, expected)
+	}
+}
+
+//a line the schema does not take is routed if a handler takes it, whatever token the schema rejects
+func TestCommandHandlerRouterOnly(t *testing.T) {
+
+	be := newTestBackend(t)
+
+	for _, line := range []string{"show version", " show version"} {
+		if err := be.CommandHandler(line, new(strings.Builder)); err != nil {
+			t.Errorf("%q: %v", line, err)
+		}
+	}
+
+	if expected := []string{"show version", "show version"}; !reflect.DeepEqual(routed, expected) {
+		t.Errorf("routed %q, expected %q", routed, expected)
+	}
+
+	routed = nil
+
+	tests := []struct {
+		line string
+		kind completer.SyntaxErrorKind
+	}{
+		{"show bogus", completer.SyntaxInvalidInput},
+		{"sh", completer.SyntaxAmbiguous},
+		{"show", completer.SyntaxIncomplete},
+		{"shutdown now", completer.SyntaxInvalidInput},
+	}
+
+	for _, test := range tests {
+
+		var out strings.Builder
+		err := be.CommandHandler(test.line, &out)
+
+		if syntaxErr, ok := err.(*completer.SyntaxError); !ok || syntaxErr.Kind != test.kind || out.Len() != 0 {
+			t.Errorf("%q: %v, %q, expected %s", test.line, err, out.String(), test.kind)
+		}
+	}
+
+	if len(routed) != 0 {
+		t.Errorf("routed %q", routed)
+	}
+}
+
+//the body of a heredoc reaches the handler of the command line before it
+func TestCommandHandlerHeredoc(t *testing.T) {
+
+	be := newTestBackend(t)
+
+	if err := be.CommandHandler("sh int eth0 <<EOF\nline 1\n\nline 3\nEOF", new(strings.Builder)); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := []string{"show interface eth0"}; !reflect.DeepEqual(routed, expected) {
+		t.Errorf("routed %q, expected %q", routed, expected)
+	}
+
+	if expected := []string{"line 1\n\nline 3"}; !reflect.DeepEqual(routedBodies, expected) {
+		t.Errorf("bodies %q, expected %q", routedBodies, expected)
+	}
+}
@@ -0,0 +1,77 @@
+//schemadoc writes the command reference of a schema, or the graphs of the params of its commands.
+//
+//	schemadoc [-format markdown|html|man] [-title title] file|directory...
+//	schemadoc -format dot|mermaid [-command keywords] file|directory...
+//
+//The output is written to the standard output. The graphs are of the commands starting with
+//the keywords given by -command, of every command if not given.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+)
+
+func main() {
+
+	format := flag.String("format", "markdown", "markdown, html, man, dot or mermaid")
+	title := flag.String("title", "Command Reference", "title of the reference")
+	command := flag.String("command", "", "keywords of the commands to graph")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-format markdown|html|man] [-title title] file|directory...\n", os.Args[0])
+		fmt.Fprintf(flag.CommandLine.Output(), "       %s -format dot|mermaid [-command keywords] file|directory...\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	formats := map[string]completer.DocFormat{
+		"markdown": completer.DocMarkdown,
+		"md":       completer.DocMarkdown,
+		"html":     completer.DocHTML,
+		"man":      completer.DocMan,
+	}
+
+	graphFormats := map[string]completer.GraphFormat{
+		"dot":     completer.GraphDOT,
+		"mermaid": completer.GraphMermaid,
+	}
+
+	docFormat, isDoc := formats[strings.ToLower(*format)]
+	graphFormat, isGraph := graphFormats[strings.ToLower(*format)]
+
+	if !isDoc && !isGraph {
+		fmt.Fprintf(os.Stderr, "unknown format %s\n", *format)
+		os.Exit(2)
+	}
+
+	var c completer.Completer
+
+	if err := c.Setup(flag.Args()...); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+
+	var err error
+
+	if isGraph {
+		err = c.WriteGraph(os.Stdout, graphFormat, *command)
+	} else {
+		err = c.WriteDoc(os.Stdout, docFormat, *title)
+	}
+
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,55 @@
+//schemalint checks completer schema files.
+//
+//	schemalint [-strict] file|directory...
+//	schemalint -jsonschema
+//
+//The files included are checked along. The format of a file is told by its extension: .yaml or .yml
+//for YAML, .toml for TOML, JSON otherwise. A directory is checked as the schema made of its files.
+//Every problem found is printed as "file: path: severity: message". The exit status is 1 if an error
+//is found, or a warning with -strict, 2 for a bad usage.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+)
+
+func main() {
+
+	strict := flag.Bool("strict", false, "fail on warnings too")
+	jsonSchema := flag.Bool("jsonschema", false, "print the JSON Schema of the schema format")
+
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: %s [-strict] file|directory...\n       %s -jsonschema\n", os.Args[0], os.Args[0])
+		flag.PrintDefaults()
+	}
+
+	flag.Parse()
+
+	if *jsonSchema {
+		os.Stdout.Write(completer.JSONSchema)
+		return
+	}
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	status := 0
+
+	for _, file := range flag.Args() {
+
+		for _, p := range completer.LintFile(file) {
+			fmt.Println(p.String())
+			if p.Severity == completer.SeverityError || *strict {
+				status = 1
+			}
+		}
+	}
+
+	os.Exit(status)
+}
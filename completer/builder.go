@@ -0,0 +1,220 @@
+package completer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+
+	"github.com/ershixiongTQL/cli-ui/router"
+)
+
+//the commands registered in Go, they are added to the schema on Setup or Reload
+var cmdRegList []schemaCommand
+var cmdRegLock sync.Mutex
+
+//copies of the registered commands, a schema prepares its own params
+func registeredCommands() []schemaCommand {
+
+	cmdRegLock.Lock()
+	defer cmdRegLock.Unlock()
+
+	return copyRegistered()
+}
+
+//the lock is expected to be held
+func copyRegistered() (commands []schemaCommand) {
+
+	for _, c := range cmdRegList {
+		c.Params = append([]schemaParam{}, c.Params...)
+		commands = append(commands, c)
+	}
+
+	return
+}
+
+//check a command and add it to the registered ones
+func registerCommand(cmd schemaCommand) error {
+
+	cmdRegLock.Lock()
+	defer cmdRegLock.Unlock()
+
+	schema := schemaTop{Commands: copyRegistered()}
+
+	cmd.source, cmd.index = "RegisterCmd", len(cmdRegList)
+
+	check := cmd
+	check.Params = append([]schemaParam{}, cmd.Params...)
+	schema.Commands = append(schema.Commands, check)
+
+	if problems := schema.prepare(false); hasErrors(problems) {
+		return &SchemaError{Problems: problems}
+	}
+
+	cmdRegList = append(cmdRegList, cmd)
+
+	return nil
+}
+
+//RegisterCmd adds a command given in the schema format, e.g. {"name": "reboot", "prefix": "reboot"}.
+//It takes effect on the next Setup or Reload of the completers.
+func RegisterCmd(raw []byte) error {
+
+	var cmd schemaCommand
+
+	if err := json.Unmarshal(schemaComment.ReplaceAll(raw, []byte{}), &cmd); err != nil {
+		return fmt.Errorf("command register error, %s", err.Error())
+	}
+
+	return registerCommand(cmd)
+}
+
+//ParamBuilder declares a param of a command in Go
+type ParamBuilder struct {
+	param schemaParam
+}
+
+//Plain declares a param taking any value
+func Plain(name string, help string) *ParamBuilder {
+	return &ParamBuilder{param: schemaParam{NameDesc: paramNameDesc{Name: name, desc: help}, Type: paramTypePlain}}
+}
+
+//Selection declares a param taking one of the values, each one "value" or "value: help"
+func Selection(name string, help string, values ...string) *ParamBuilder {
+
+	var sels []interface{}
+	for _, v := range values {
+		sels = append(sels, v)
+	}
+
+	return &ParamBuilder{param: schemaParam{NameDesc: paramNameDesc{Name: name, desc: help}, Type: paramTypeSelection, Range: sels}}
+}
+
+//Integer declares a param taking a number from min to max
+func Integer(name string, help string, min int64, max int64) *ParamBuilder {
+	return &ParamBuilder{param: schemaParam{NameDesc: paramNameDesc{Name: name, desc: help}, Type: paramTypeInteger, Range: fmt.Sprintf("%d-%d", min, max)}}
+}
+
+//Optional lets the param be left out
+func (p *ParamBuilder) Optional() *ParamBuilder {
+	p.param.Optional = true
+	return p
+}
+
+//Unique keeps the param from being given twice
+func (p *ParamBuilder) Unique() *ParamBuilder {
+	p.param.Unique = true
+	return p
+}
+
+//Condition makes the param given whenever all of the conditions are met instead of in order
+func (p *ParamBuilder) Condition(conditions ...string) *ParamBuilder {
+	p.param.Condition = append(p.param.Condition, conditions...)
+	return p
+}
+
+//CommandBuilder declares a command in Go, with the handler it is run by, e.g.
+//
+//	err := completer.Command("show interface").
+//		Comment("Show interfaces").
+//		Param(completer.Plain("name", "interface name")).
+//		Param(completer.Selection("detail", "", "brief: brief info", "detail: detailed info").Condition("- eq name").Optional()).
+//		Handler(showInterface).
+//		Register()
+type CommandBuilder struct {
+	cmd     schemaCommand
+	handler cmdHandler
+	err     error
+}
+
+//cmdHandler is what a command registered in Go is run by, one of the handlers is set
+type cmdHandler struct {
+	handler         router.DefaultHandler
+	progressHandler router.ProgressHandler
+	args            func(found *cmdMatch, w io.StringWriter) //of Args, the values are taken from the match
+}
+
+//Command starts the declaration of a command by its keywords, e.g. "show interface"
+func Command(prefix string) *CommandBuilder {
+	return &CommandBuilder{cmd: schemaCommand{Name: prefix, Prefix: prefix}}
+}
+
+//Name of the command, the prefix if not given
+func (b *CommandBuilder) Name(name string) *CommandBuilder {
+	b.cmd.Name = name
+	return b
+}
+
+//Comment tells what the command does
+func (b *CommandBuilder) Comment(comment string) *CommandBuilder {
+	b.cmd.Comment = comment
+	return b
+}
+
+//Param adds a param after the ones added before
+func (b *CommandBuilder) Param(param *ParamBuilder) *CommandBuilder {
+	b.cmd.Params = append(b.cmd.Params, param.param)
+	return b
+}
+
+//Handler is called when the command is executed
+func (b *CommandBuilder) Handler(handler router.DefaultHandler) *CommandBuilder {
+	b.handler.handler = handler
+	return b
+}
+
+//ProgressHandler is called when the command is executed, with a progress bar
+func (b *CommandBuilder) ProgressHandler(handler router.ProgressHandler) *CommandBuilder {
+	b.handler.progressHandler = handler
+	return b
+}
+
+//Register adds the command. It takes effect on the next Setup or Reload of the completers, which run
+//the command lines of the command by its handler, see RunRegistered.
+func (b *CommandBuilder) Register() (err error) {
+
+	if b.err != nil {
+		return b.err
+	}
+
+	handlers := 0
+	for _, set := range []bool{b.handler.handler != nil, b.handler.progressHandler != nil, b.handler.args != nil} {
+		if set {
+			handlers++
+		}
+	}
+
+	if handlers > 1 {
+		return fmt.Errorf("command %s: more than one handler", b.cmd.Name)
+	}
+
+	cmd := b.cmd
+	if handlers != 0 {
+		handler := b.handler
+		cmd.handler = &handler
+	}
+
+	return registerCommand(cmd)
+}
+
+//RunRegistered runs a command line by the handler of the command registered in Go it matches, if it
+//is one. The line is run by that command only, not by another one its keywords are the start of.
+//The line is expected to be validated, body is the multi-line block given along with it.
+func (s *Completer) RunRegistered(input string, body string, w io.StringWriter) (handled bool) {
+
+	found := s.current().lineMatch(CmdlineField(input).Strings())
+	if found == nil || found.command.handler == nil {
+		return false
+	}
+
+	h := found.command.handler
+
+	if h.args != nil {
+		h.args(found, w)
+	} else {
+		router.Call(found.command.Name, strings.TrimLeft(input, " \t"), body, h.handler, h.progressHandler, w)
+	}
+
+	return true
+}
@@ -0,0 +1,183 @@
+package completer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/ershixiongTQL/cli-ui/router"
+)
+
+//keep the commands registered by a test out of the others
+func isolateRegistered(t *testing.T) {
+
+	cmdRegLock.Lock()
+	saved := cmdRegList
+	cmdRegList = nil
+	cmdRegLock.Unlock()
+
+	t.Cleanup(func() {
+		cmdRegLock.Lock()
+		cmdRegList = saved
+		cmdRegLock.Unlock()
+	})
+}
+
+func registeredPrefixes() (prefixes []string) {
+	for _, c := range registeredCommands() {
+		prefixes = append(prefixes, c.Prefix)
+	}
+	return
+}
+
+func TestRegister(t *testing.T) {
+
+	isolateRegistered(t)
+
+	handler := func(router.Input, io.StringWriter) {}
+
+	prefix := "builder reboot"
+
+	err := Command(prefix).
+		Comment("Reboot").
+		Param(Selection("when", "", "now", "later").Optional()).
+		Param(Integer("delay", "seconds", 1, 60).Condition("{when} eq later")).
+		Handler(handler).
+		Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	for args, valid := range map[string]bool{"": true, " later 5": true, " later": false, " now 5": false} {
+		if err := c.Validate(prefix + args); (err == nil) != valid {
+			t.Errorf("%q: %v", prefix+args, err)
+		}
+	}
+
+	//the same prefix twice
+	if err := Command(prefix).Handler(handler).Register(); err == nil {
+		t.Errorf("registered twice")
+	}
+
+	if err := Command("builder bad").Param(Plain("x", "").Condition("count(")).Register(); err == nil {
+		t.Errorf("registered with a bad condition")
+	}
+
+	if err := Command("builder both").Handler(handler).ProgressHandler(func(router.Input, io.StringWriter, func(float32)) error { return nil }).Register(); err == nil {
+		t.Errorf("registered with two handlers")
+	}
+
+	if prefixes := registeredPrefixes(); len(prefixes) != 1 {
+		t.Errorf("registered %q", prefixes)
+	}
+}
+
+//a line is run by the command it matches only, not by another one its keywords are the start of
+func TestRunRegisteredOverlap(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var ran []string
+	handler := func(name string) router.DefaultHandler {
+		return func(input router.Input, w io.StringWriter) {
+			ran = append(ran, name+": "+input.GetRaw())
+		}
+	}
+
+	for _, b := range []*CommandBuilder{
+		Command("rvshow").Param(Plain("what", "").Optional()).Handler(handler("rvshow")),
+		Command("rvshow interface").Param(Plain("name", "")).Handler(handler("rvshow interface")),
+		Command("rvshow interface brief").Handler(handler("rvshow interface brief")),
+	} {
+		if err := b.Register(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := RegisterCmd([]byte(`{"name": "rvclear", "prefix": "rvclear"}`)); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	tests := []struct {
+		line string
+		ran  []string //nil if the line is not run
+	}{
+		{"rvshow", []string{"rvshow: rvshow"}},
+		{"rvshow version", []string{"rvshow: rvshow version"}},
+		{"rvshow interface eth0", []string{"rvshow interface: rvshow interface eth0"}},
+		{"rvsh int eth0", []string{"rvshow interface: rvsh int eth0"}},
+		{"  rvshow interface eth0", []string{"rvshow interface: rvshow interface eth0"}},
+		{"rvshow interface brief", []string{"rvshow interface brief: rvshow interface brief"}},
+		{"rvshow interface", nil},
+		{"rvclear", nil}, //no handler
+		{"show vlan 1", nil},
+	}
+
+	for _, test := range tests {
+
+		ran = nil
+
+		if handled := c.RunRegistered(test.line, "", new(strings.Builder)); handled != (test.ran != nil) || !reflect.DeepEqual(ran, test.ran) {
+			t.Errorf("%q: handled %v, ran %q, expected %q", test.line, handled, ran, test.ran)
+		}
+	}
+}
+
+func TestRunRegisteredProgress(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var body string
+	err := Command("rvupgrade").ProgressHandler(func(input router.Input, w io.StringWriter, progress func(float32)) error {
+		body = input.GetBody()
+		progress(0.5)
+		return nil
+	}).Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	var out strings.Builder
+	if !c.RunRegistered("rvupgrade", "image", &out) || body != "image" || !strings.Contains(out.String(), " 50.00%") {
+		t.Errorf("body %q, output %q", body, out.String())
+	}
+}
+
+func TestRegisterConcurrent(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 20)
+
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- RegisterCmd([]byte(fmt.Sprintf(`{"name": "same %d", "prefix": "builder same"}`, i)))
+		}(i)
+	}
+
+	wg.Wait()
+	close(errs)
+
+	registered := 0
+	for err := range errs {
+		if err == nil {
+			registered++
+		}
+	}
+
+	if registered != 1 || len(registeredPrefixes()) != 1 {
+		t.Errorf("the same prefix registered %d times", registered)
+	}
+}
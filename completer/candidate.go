@@ -0,0 +1,163 @@
+package completer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+type CandidateKind int
+
+const (
+	CandidateKeyword  CandidateKind = iota //a keyword of a command prefix
+	CandidateValue                         //a value of a selection
+	CandidateArgument                      //what a plain or integer param takes, e.g. "<name>"
+)
+
+func (k CandidateKind) String() string {
+	switch k {
+	case CandidateKeyword:
+		return "keyword"
+	case CandidateValue:
+		return "value"
+	case CandidateArgument:
+		return "argument"
+	default:
+		return fmt.Sprintf("CandidateKind(%d)", int(k))
+	}
+}
+
+//Candidate is a way to complete the last field of a command line
+type Candidate struct {
+	Text        string //what to add to the line, or to replace the end of the line with
+	Value       string //the keyword or the value the field is completed to
+	Description string
+	Kind        CandidateKind
+}
+
+//the candidates of the commands, one for each text, sorted by it. The descriptions of the ones of the same text are merged.
+func mergeCandidates(all []Candidate) (merged []Candidate) {
+
+	index := make(map[string]int)
+
+	for _, c := range all {
+
+		i, exist := index[c.Text]
+		if !exist {
+			index[c.Text] = len(merged)
+			merged = append(merged, c)
+			continue
+		}
+
+		if m := &merged[i]; c.Description != "" && !strings.Contains(" / "+m.Description+" / ", " / "+c.Description+" / ") {
+			if m.Description == "" {
+				m.Description = c.Description
+			} else {
+				m.Description += " / " + c.Description
+			}
+		}
+	}
+
+	sort.SliceStable(merged, func(i, j int) bool {
+		return merged[i].Text < merged[j].Text
+	})
+
+	return
+}
+
+//the candidates completing the last field by prefix. If they have a common prefix, it is the only one to add.
+func (s *Completer) prefixCandidates(input string) (candidates []Candidate) {
+
+	//get "completions" of next param if the last field is done, otherwise, get "completions" of "this" param
+	segs, next, quote := completeFields(input)
+	schema := s.current()
+
+	for _, command := range schema.Commands {
+		//the built-in help completes the keywords of the other commands
+		if words, ok := command.helpWords(segs, next); ok && command.builtin {
+			candidates = append(candidates, schema.helpComplete(words, next)...)
+			continue
+		}
+		//combine "completions" from each commands
+		candidates = append(candidates, command.complete(segs, next, quote)...)
+	}
+
+	candidates = mergeCandidates(candidates) //remove duplicated "completions"
+
+	var texts []string
+	for _, c := range candidates {
+		texts = append(texts, c.Text)
+	}
+
+	if commonPrefix := trimEscape(LongestCommonPrefix(texts)); len(commonPrefix) != 0 {
+		//found "loggest common prefix" and return it
+		if len(candidates) == 1 {
+			return candidates
+		}
+		return []Candidate{{Text: commonPrefix, Value: lastField(input + commonPrefix), Kind: candidates[0].Kind}}
+	}
+
+	for _, c := range candidates {
+		if c.Text == " " {
+			return []Candidate{c} //found a "completion" which is a single space, return it
+		}
+	}
+
+	s.rankCandidates(candidates)
+
+	return
+}
+
+//the last field of a command line, "" if none
+func lastField(input string) string {
+
+	if fields := CmdlineField(input).Strings(); len(fields) != 0 {
+		return fields[len(fields)-1]
+	}
+
+	return ""
+}
+
+//GetCompletes returns what completes the command line by prefix: the text to add to it if there is one,
+//or the ones to choose from.
+func (s *Completer) GetCompletes(input string) (completions []string) {
+
+	for _, c := range s.prefixCandidates(input) {
+		completions = append(completions, c.Text)
+	}
+
+	return
+}
+
+//Complete returns the candidates completing a command line, ranked, and how many bytes at the end of the line
+//they replace. A candidate is added to the line if replace is 0. Otherwise it replaces the last field, for it is
+//a keyword or a value the field is a subsequence or a typo of, found in MatchFuzzy mode. If there is a text to
+//add for sure, e.g. the common prefix of the candidates, it is the only candidate.
+func (s *Completer) Complete(input string) (candidates []Candidate, replace int) {
+
+	if candidates = s.prefixCandidates(input); len(candidates) != 0 || s.matchMode() != MatchFuzzy {
+		return
+	}
+
+	fields := CmdlineField(input)
+	segs := fields.Strings()
+
+	if len(segs) == 0 || fields.unclosed != 0 {
+		return
+	}
+
+	last := fields.segs[len(segs)-1]
+	if last.end < len(input) {
+		return
+	}
+
+	for _, m := range s.fuzzyRank(last.content, s.current().expectedHelps(segs[:len(segs)-1])) {
+		candidates = append(candidates, Candidate{Text: Quote(m.word) + " ", Value: m.word, Description: m.help.info, Kind: m.help.kind})
+	}
+
+	if len(candidates) != 0 {
+		replace = len(input) - last.start
+	}
+
+	return
+}
@@ -0,0 +1,106 @@
+package completer
+
+import (
+	"fmt"
+)
+
+//TokenClass is what a token of a command line is taken as by the schema
+type TokenClass int
+
+const (
+	TokenKeyword   TokenClass = iota //a keyword of a command prefix, or an abbreviation of it
+	TokenValue                       //a value of a selection, or an abbreviation of it, or a number in the range of an integer param
+	TokenArgument                    //the value of a plain param
+	TokenInvalid                     //the first token no command takes
+	TokenUnchecked                   //a token after an invalid one
+)
+
+func (c TokenClass) String() string {
+	switch c {
+	case TokenKeyword:
+		return "keyword"
+	case TokenValue:
+		return "value"
+	case TokenArgument:
+		return "argument"
+	case TokenInvalid:
+		return "invalid"
+	case TokenUnchecked:
+		return "unchecked"
+	default:
+		return fmt.Sprintf("TokenClass(%d)", int(c))
+	}
+}
+
+//Token is a field of a command line and what it is taken as
+type Token struct {
+	Text  string //the field as it is in the line
+	Start int    //byte offset of the field in the line
+	End   int    //byte offset after the field
+	Class TokenClass
+}
+
+//what a token is taken as by a way of taking the command line
+func (m *cmdMatch) tokenClass(i int) TokenClass {
+
+	p := m.params[i]
+
+	switch {
+	case p == nil:
+		return TokenKeyword
+	case p.Type == paramTypePlain:
+		return TokenArgument
+	default:
+		return TokenValue
+	}
+}
+
+//the classes of the tokens, the best way of taking them decides. The tokens are taken as far as
+//a command can take them, the next one is invalid.
+func (t *schemaTop) classify(segs []string) (classes []TokenClass) {
+
+	progress := 0
+
+	for i := range t.Commands {
+		if _, p := t.Commands[i].match(segs); p > progress {
+			progress = p
+		}
+	}
+
+	var matches []*cmdMatch
+
+	if progress != 0 {
+		for i := range t.Commands {
+			found, _ := t.Commands[i].match(segs[:progress])
+			matches = append(matches, found...)
+		}
+		matches = bestMatches(matches, progress)
+	}
+
+	for i := range segs {
+		switch {
+		case i < progress:
+			classes = append(classes, matches[0].tokenClass(i))
+		case i == progress:
+			classes = append(classes, TokenInvalid)
+		default:
+			classes = append(classes, TokenUnchecked)
+		}
+	}
+
+	return
+}
+
+//Classify tells what each token of a command line is taken as, e.g. for the line to be colored as
+//it is typed. A token being typed is taken as what it is an abbreviation of.
+func (s *Completer) Classify(input string) (tokens []Token) {
+
+	fields := CmdlineField(input)
+	classes := s.current().classify(fields.Strings())
+
+	for i, seg := range fields.Segs() {
+		tokens = append(tokens, Token{Text: seg.Raw(), Start: seg.Offset(), End: seg.End(), Class: classes[i]})
+	}
+
+	return
+}
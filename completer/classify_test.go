@@ -0,0 +1,56 @@
+package completer
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	tests := []struct {
+		input   string
+		classes []TokenClass
+	}{
+		{"", nil},
+		{"show interface eth0 detail", []TokenClass{TokenKeyword, TokenKeyword, TokenArgument, TokenValue}},
+		//abbreviations are taken as what they are abbreviations of
+		{"sh int eth0 br", []TokenClass{TokenKeyword, TokenKeyword, TokenArgument, TokenValue}},
+		{"show vlan 100", []TokenClass{TokenKeyword, TokenKeyword, TokenValue}},
+		{"set speed 100 force", []TokenClass{TokenKeyword, TokenValue, TokenArgument, TokenValue}},
+		//the first token no command takes is invalid, the ones after it are not checked
+		{"show vlan 5000 x", []TokenClass{TokenKeyword, TokenKeyword, TokenInvalid, TokenUnchecked}},
+		{"nothing here", []TokenClass{TokenInvalid, TokenUnchecked}},
+		{"show interface eth0 bogus", []TokenClass{TokenKeyword, TokenKeyword, TokenArgument, TokenInvalid}},
+	}
+
+	for _, test := range tests {
+
+		var classes []TokenClass
+		for _, token := range c.Classify(test.input) {
+			classes = append(classes, token.Class)
+		}
+
+		if !reflect.DeepEqual(classes, test.classes) {
+			t.Errorf("%q: classified as %v, expected %v", test.input, classes, test.classes)
+		}
+	}
+}
+
+//the tokens are where the fields are in the line, as they are in it
+func TestClassifyOffsets(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	tokens := c.Classify(`  sh  int "eth 0"`)
+	expected := []Token{
+		{Text: "sh", Start: 2, End: 4, Class: TokenKeyword},
+		{Text: "int", Start: 6, End: 9, Class: TokenKeyword},
+		{Text: `"eth 0"`, Start: 10, End: 17, Class: TokenArgument},
+	}
+
+	if !reflect.DeepEqual(tokens, expected) {
+		t.Errorf("classified as %+v, expected %+v", tokens, expected)
+	}
+}
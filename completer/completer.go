@@ -3,13 +3,17 @@ package completer
 import (
 	"bytes"
 	"container/list"
-	"encoding/json"
 	"fmt"
+	"io"
+	"io/fs"
 	"io/ioutil"
 	"reflect"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"unicode/utf8"
 )
 
 type paramType int
@@ -17,6 +21,7 @@ type paramType int
 const (
 	paramTypeSelection paramType = iota
 	paramTypePlain
+	paramTypeInteger
 )
 
 func (t paramType) String() string {
@@ -25,6 +30,8 @@ func (t paramType) String() string {
 		return "SELECTION"
 	case paramTypePlain:
 		return "PLAIN"
+	case paramTypeInteger:
+		return "INTEGER"
 	default:
 		return "???"
 	}
@@ -39,6 +46,8 @@ func (t *paramType) UnmarshalJSON(data []byte) (err error) {
 		*t = paramTypeSelection
 	case "\"PLAIN\"":
 		*t = paramTypePlain
+	case "\"INTEGER\"", "\"INT\"":
+		*t = paramTypeInteger
 	default:
 		return fmt.Errorf("invalid param type: |%s|", str)
 	}
@@ -84,6 +93,9 @@ type schemaParam struct {
 	Optional  bool          `json:"optional"`
 	Condition []string      `json:"condition"`
 	Unique    bool          `json:"uniq"`
+	Ref       string        `json:"ref"` //name of the shared param this one is
+
+	conditions []*condition //compiled from Condition
 }
 
 func (p *schemaParam) getHelps() (helps []cmdHelp) {
@@ -103,180 +115,51 @@ func (p *schemaParam) getHelps() (helps []cmdHelp) {
 				}
 			}
 
-			helps = append(helps, cmdHelp{whatToInput: sels[i], info: info})
+			helps = append(helps, cmdHelp{whatToInput: sels[i], info: info, kind: CandidateValue})
 
 		}
 
 	case paramTypePlain:
-		helps = append(helps, cmdHelp{whatToInput: "<" + p.NameDesc.Name + ">", info: p.NameDesc.desc})
-	}
-
-	return
-}
-
-func conditionCheckEqual(p *schemaParam, context *cmdContext, toCheck, given string, notEq bool) (ok bool) {
-
-	var nameMode bool
-
-	if !strings.HasPrefix(toCheck, "{") && !strings.HasSuffix(toCheck, "}") {
-		nameMode = true
-	}
-
-	toCheck = strings.Trim(toCheck, "{}")
-
-	var equal bool
-
-	if strings.HasPrefix(toCheck, "-") {
-		//relative mode
-		//TODO: support offset number
-
-		lastName, lastValue, ok := context.getLast()
-
-		if !ok {
-			// fmt.Printf("no context last\n")
-			return notEq
-		}
-
-		if (nameMode && (lastName == given)) || (!nameMode && (lastValue == given)) {
-			equal = true
-		}
-
-		// fmt.Printf("checking equal, %s, lastName %s lastValue %s, name mode %t, ok %t\n", p.NameDesc.Name, lastName, lastValue, nameMode, equal && !notEq)
-
-	} else {
+		helps = append(helps, cmdHelp{whatToInput: "<" + p.NameDesc.Name + ">", info: p.NameDesc.desc, kind: CandidateArgument})
 
-		if nameMode {
-			return toCheck == given
+	case paramTypeInteger:
+		what := "<" + p.NameDesc.Name + ">"
+		if min, max, bounded, err := rangeDecodeInteger(p.Range); err == nil && bounded {
+			what = fmt.Sprintf("<%d-%d>", min, max)
 		}
-
-		exists := context.lookup(toCheck)
-
-		for _, exist := range exists {
-			if exist == given {
-				equal = true
-				break
-			}
-		}
-
+		helps = append(helps, cmdHelp{whatToInput: what, info: p.NameDesc.desc, kind: CandidateArgument})
 	}
 
-	return notEq == !equal
+	return
 }
 
-func conditionCheckIn(p *schemaParam, context *cmdContext, toCheck, given string, notIn bool) (ok bool) {
-
-	var nameMode bool
-
-	if !strings.HasPrefix(toCheck, "{") && !strings.HasSuffix(toCheck, "}") {
-		nameMode = true
-	}
-
-	toCheck = strings.Trim(toCheck, "{}")
-
-	givens := strings.Fields(given)
-	var inGivens bool
-
-	if strings.HasPrefix(toCheck, "-") {
-		//relative mode
-		//TODO: support offset number
-
-		lastName, lastValue, ok := context.getLast()
-
-		if !ok {
-			return notIn
-		}
-
-		for _, g := range givens {
-			if (nameMode && g == lastName) || (!nameMode && g == lastValue) {
-				inGivens = true
-			}
-		}
-
-	} else {
+var integerRange = regexp.MustCompile(`^\s*(-?\d+)\s*-\s*(-?\d+)\s*$`)
 
-		if nameMode {
-			for _, g := range givens {
-				if toCheck == g {
-					inGivens = true
-				}
-			}
-		} else {
+//the range of an integer param is "min-max", any integer if not given
+func rangeDecodeInteger(r paramRange) (min int64, max int64, bounded bool, err error) {
 
-			exists := context.lookup(toCheck)
-			for _, exist := range exists {
-				for _, g := range givens {
-					if exist == g {
-						inGivens = true
-						break
-					}
-				}
-				if inGivens {
-					break
-				}
-			}
-		}
+	if r == nil {
+		return
 	}
 
-	return inGivens == !notIn
-}
-
-func (p *schemaParam) conditionCheck(context *cmdContext) bool {
-
-	// fmt.Printf("condition for param %s: %s\n", p.NameDesc.Name, p.Condition)
-
-	if len(p.Condition) == 0 {
-		return true
+	str, ok := r.(string)
+	if !ok {
+		return 0, 0, false, fmt.Errorf("range of an integer must be \"min-max\"")
 	}
 
-	if p.Unique && context.count(p.NameDesc.Name) > 0 {
-		return false
+	found := integerRange.FindStringSubmatch(str)
+	if found == nil {
+		return 0, 0, false, fmt.Errorf("range of an integer must be \"min-max\"")
 	}
 
-	condEqual, _ := regexp.Compile(`(\{?\S+\}?)\s*(?:(not)\s+)?eq\s+(\S+)\s*`)
-	condIn, _ := regexp.Compile(`(\{?\S+\}?)\s*(?:(not)\s+)?in\s+(.*)`)
-
-	for _, c := range p.Condition {
-
-		var segs []string
-
-		if c == "*" {
-			//means any conditions
-			continue
-		}
-
-		segs = condEqual.FindStringSubmatch(c)
-		if segs != nil {
-			toCheck := segs[1]
-			given := segs[3]
+	min, errMin := strconv.ParseInt(found[1], 10, 64)
+	max, errMax := strconv.ParseInt(found[2], 10, 64)
 
-			if !conditionCheckEqual(p, context, toCheck, given, segs[2] == "not") {
-				return false
-			} else {
-				// fmt.Printf("cond %s eq check of param %s: toCheck %s given %s, ok\n", segs[2], p.NameDesc.Name, toCheck, given)
-			}
-
-			continue
-		}
-
-		segs = condIn.FindStringSubmatch(c)
-		if segs != nil {
-			toCheck := segs[1]
-			given := segs[3]
-
-			if !conditionCheckIn(p, context, toCheck, given, segs[2] == "not") {
-				return false
-			} else {
-				// fmt.Printf("cond %s in check of param %s: toCheck %s given %s, ok\n", segs[2], p.NameDesc.Name, toCheck, given)
-			}
-
-			continue
-		}
-
-		// fmt.Printf("condition format invalid: %s\n", c)
-		return false
+	if errMin != nil || errMax != nil || min > max {
+		return 0, 0, false, fmt.Errorf("invalid integer range \"%s\"", str)
 	}
 
-	return true
+	return min, max, true, nil
 }
 
 func rangeDecodeSelection(r paramRange) (names []string, descs []string, err error) {
@@ -344,48 +227,77 @@ func (param *schemaParam) checkValue(value string) bool {
 	case paramTypePlain:
 		//Plain type can handle any kind of value
 		return true
+	case paramTypeInteger:
+		n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return false
+		}
+		min, max, bounded, _ := rangeDecodeInteger(param.Range)
+		return !bounded || (n >= min && n <= max)
 	case paramTypeSelection:
 		sels, _, _ := rangeDecodeSelection(param.Range)
-		if strings.Contains(" "+strings.ToLower(strings.Join(sels, " "))+" ", strings.ToLower(" "+strings.TrimSpace(value)+" ")) {
-			return true
+		for _, sel := range sels {
+			if sel != "" && strings.EqualFold(sel, strings.TrimSpace(value)) {
+				return true
+			}
 		}
 	}
 
 	return false
 }
 
-func (param *schemaParam) getCompletions(src string) (completions []string) {
+//the rest of a value to add to the field of a command line being given, which is in the quote open
+//or out of quotes if quote is 0. The field is closed and followed by a space.
+func completionSuffix(rest string, quote byte, fresh bool) string {
 
-	// fmt.Printf("getting completions of param: %s\n", param.NameDesc.Name)
+	switch {
+	case quote != 0:
+		return strings.NewReplacer("\\", "\\\\", string(quote), "\\"+string(quote)).Replace(rest) + string(quote) + " "
+	case fresh:
+		return Quote(rest) + " "
+	}
+
+	var escaped strings.Builder
 
-	if param.Type == paramTypePlain {
+	for _, r := range rest {
+		if r < utf8.RuneSelf && (isFieldSpace(byte(r)) || isQuote(byte(r)) || r == '\\') {
+			escaped.WriteByte('\\')
+		}
+		escaped.WriteRune(r)
+	}
 
+	return escaped.String() + " "
+}
+
+//completions of the param for the field being given, src is its content so far and quote the quote
+//it is in, 0 if none. They are what to add to the field, quoted or escaped as needed.
+func (param *schemaParam) getCompletions(src string, quote byte) (completions []Candidate) {
+
+	if param.Type != paramTypeSelection {
 		return
+	}
 
-	} else if param.Type == paramTypeSelection {
+	sels, descs, _ := rangeDecodeSelection(param.Range)
 
-		sels, _, _ := rangeDecodeSelection(param.Range)
+	srcLen := len([]rune(src))
+	seen := make(map[string]bool)
 
-		if len(sels) == 0 {
-			return
+	for i, s := range sels {
+
+		if s == "" || seen[s] || !strings.HasPrefix(strings.ToLower(s), strings.ToLower(src)) {
+			continue
 		}
+		seen[s] = true
 
-		sels = stringsUniq(sels)
+		c := Candidate{Value: s, Description: descs[i], Kind: CandidateValue}
 
-		for _, s := range sels {
-			if strings.HasPrefix(strings.ToLower(s), strings.ToLower(src)) {
-				completions = append(completions, s)
-			}
+		if comp := []rune(s); len(comp) == srcLen && quote == 0 {
+			c.Text = " "
+		} else {
+			c.Text = completionSuffix(string(comp[srcLen:]), quote, src == "" && quote == 0)
 		}
 
-		for i := 0; i < len(completions); i++ {
-			// fmt.Printf("comp %d is |%s| was |%s| src is |%s|\n", i, completions[i][len(src):]+" ", completions[i], src)
-			if len(completions[i]) == len(src) {
-				completions[i] = " "
-			} else {
-				completions[i] = completions[i][len(src):] + " "
-			}
-		}
+		completions = append(completions, c)
 	}
 
 	return
@@ -398,6 +310,10 @@ type schemaCommand struct {
 	Comment      string        `json:"comment"`
 	staticParams []*schemaParam
 	dynamParams  []*schemaParam
+	source       string      //the file it is loaded from
+	index        int         //in the commands of the file
+	builtin      bool        //the built-in help command
+	handler      *cmdHandler //what a command registered in Go is run by, nil if none
 }
 
 func (c *schemaCommand) prefixComplete(inputs *[]string, completeNext bool) (completeStr string, fulls string, prefixMatch bool) {
@@ -443,7 +359,7 @@ func (c *schemaCommand) prefixComplete(inputs *[]string, completeNext bool) (com
 				}
 
 			} else {
-				completeStr = (prefixSegs[inputsLen-1] + " ")[len(lastInput):]
+				completeStr = string([]rune(prefixSegs[inputsLen-1] + " ")[len([]rune(lastInput)):])
 				fulls = prefixSegs[inputsLen-1]
 				return
 			}
@@ -474,13 +390,8 @@ func (path *logicPath) step(values []string, next bool) (thisPath *logicPath) {
 
 	if path.param == nil {
 
-		if len(path.command.staticParams) != 0 {
-			path.addNext(path.command.staticParams[0], 0)
-		}
-		for _, p := range path.command.dynamParams {
-			if p.conditionCheck(path.context) {
-				path.addNext(p, 0)
-			}
+		for _, n := range path.command.nextParams(nil, 0, path.context) {
+			path.addNext(n.param, n.staticParamPos)
 		}
 
 		elem := path.nexts.Front()
@@ -496,10 +407,19 @@ func (path *logicPath) step(values []string, next bool) (thisPath *logicPath) {
 		return
 	}
 
-	path.inputVal = values[0]
-	path.context.append(path.param.NameDesc.Name, values[0])
+	value := values[0]
 
-	if !path.param.checkValue(values[0]) {
+	if len(values) != 1 || next {
+		//a token already given may be a unique abbreviation
+		if words, _ := path.param.matchValue(value); len(words) == 1 {
+			value = words[0]
+		}
+	}
+
+	path.inputVal = value
+	path.context.append(path.param.NameDesc.Name, value)
+
+	if !path.param.checkValue(value) {
 		if len(values) != 1 || next {
 			*path.invalid = true
 		}
@@ -510,18 +430,8 @@ func (path *logicPath) step(values []string, next bool) (thisPath *logicPath) {
 		}
 	}
 
-	if path.staticParamPos+1 < len(path.command.staticParams) {
-		for _, p := range path.command.staticParams {
-			if p == path.param {
-				path.addNext(path.command.staticParams[path.staticParamPos+1], path.staticParamPos+1)
-			}
-		}
-	}
-
-	for _, p := range path.command.dynamParams {
-		if p.conditionCheck(path.context) {
-			path.addNext(p, path.staticParamPos)
-		}
+	for _, n := range path.command.nextParams(path.param, path.staticParamPos, path.context) {
+		path.addNext(n.param, n.staticParamPos)
 	}
 
 	if len(values) > 1 {
@@ -541,7 +451,7 @@ func (path *logicPath) step(values []string, next bool) (thisPath *logicPath) {
 	return
 }
 
-func (path *logicPath) getComplete() (completions []string) {
+func (path *logicPath) getComplete(quote byte) (completions []Candidate) {
 
 	if *path.invalid {
 		return
@@ -549,13 +459,13 @@ func (path *logicPath) getComplete() (completions []string) {
 
 	if path.nexts.Len() == 0 {
 
-		return append(completions, path.param.getCompletions(path.inputVal)...)
+		return append(completions, path.param.getCompletions(path.inputVal, quote)...)
 
 	} else {
 		elem := path.nexts.Front()
 		for elem != nil {
 			child := elem.Value.(*logicPath)
-			completions = append(completions, child.getComplete()...)
+			completions = append(completions, child.getComplete(quote)...)
 			elem = elem.Next()
 		}
 	}
@@ -566,6 +476,7 @@ func (path *logicPath) getComplete() (completions []string) {
 type cmdHelp struct {
 	whatToInput string
 	info        string
+	kind        CandidateKind
 }
 
 func (path *logicPath) getHelps(next bool) (helps []cmdHelp) {
@@ -613,7 +524,7 @@ func newLogicalPath(command *schemaCommand, param *schemaParam, staticParamPos i
 	return
 }
 
-func (c *schemaCommand) paramsComplete(inputs *[]string, completeNext bool) (ret []string) {
+func (c *schemaCommand) paramsComplete(inputs *[]string, completeNext bool, quote byte) (ret []Candidate) {
 
 	if len(c.Params) == 0 {
 		return
@@ -623,16 +534,10 @@ func (c *schemaCommand) paramsComplete(inputs *[]string, completeNext bool) (ret
 
 	rootPath.step(*inputs, completeNext)
 
-	comps := rootPath.getComplete()
+	comps := rootPath.getComplete(quote)
 
 	for _, c := range comps {
-		if c == "" {
-			if completeNext {
-				return
-			} else {
-				return []string{" "}
-			}
-		} else if c == " " && completeNext {
+		if c.Text == " " && completeNext {
 			return
 		}
 	}
@@ -649,19 +554,20 @@ func (c *schemaCommand) paramsHelp(inputs *[]string, completeNext bool) (helps [
 	return newLogicalPath(c, nil, 0, nil).step(*inputs, completeNext).getHelps(completeNext)
 }
 
-func (c *schemaCommand) complete(inputs []string, next bool) (completions []string) {
+//completions of the command line, quote is the one the last field is in, 0 if none
+func (c *schemaCommand) complete(inputs []string, next bool, quote byte) (completions []Candidate) {
 
-	prefixComp, _, match := c.prefixComplete(&inputs, next)
+	prefixComp, keyword, match := c.prefixComplete(&inputs, next)
 
 	if !match {
 		return
 	}
 
 	if prefixComp != "" {
-		return []string{prefixComp}
+		return []Candidate{{Text: prefixComp, Value: keyword, Description: strings.Title(c.Name), Kind: CandidateKeyword}}
 	}
 
-	return c.paramsComplete(&inputs, next)
+	return c.paramsComplete(&inputs, next, quote)
 }
 
 func (c *schemaCommand) help(inputs []string, next bool) (helps []cmdHelp) {
@@ -672,7 +578,7 @@ func (c *schemaCommand) help(inputs []string, next bool) (helps []cmdHelp) {
 		if prefixHelp == "" {
 			helps = c.paramsHelp(&inputs, next)
 		} else {
-			helps = append(helps, cmdHelp{whatToInput: prefixHelp, info: strings.Title(c.Name)})
+			helps = append(helps, cmdHelp{whatToInput: prefixHelp, info: strings.Title(c.Name), kind: CandidateKeyword})
 		}
 	}
 
@@ -680,80 +586,163 @@ func (c *schemaCommand) help(inputs []string, next bool) (helps []cmdHelp) {
 }
 
 type schemaTop struct {
-	Commands []schemaCommand `json:"commands"`
+	Version  int                    `json:"version"` //SchemaVersion if not given
+	Include  []string               `json:"include"` //other schema files, directories or glob patterns
+	Params   map[string]schemaParam `json:"params"`  //shared params the commands refer to by "ref"
+	Commands []schemaCommand        `json:"commands"`
 }
 
+//Completer completes, checks and explains the command lines of a schema. The built-in help command is
+//added to the schema unless a command of it starts with the same keyword, or SetHelpCommand turns it off.
 type Completer struct {
-	source []byte
-	schema schemaTop
+	lock    sync.RWMutex
+	schema  *schemaTop
+	load    func(l *schemaLoader) //loads the schema, again on reload
+	files   schemaFiles           //where the schema files are read from
+	watched []string              //the files and directories to watch for changes, the included ones too
+	mode    MatchMode
+	usage   wordUsage //the words of the command lines executed recently
+	noHelp  bool      //the built-in help command is left out
 }
 
-func (s *Completer) Setup(filePath string) (err error) {
-	s.source, err = ioutil.ReadFile(filePath)
+//the schema in use, a reload swaps in a new one and leaves this one as it is
+func (s *Completer) current() *schemaTop {
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.schema == nil {
+		return &schemaTop{}
+	}
+
+	return s.schema
+}
+
+func (s *Completer) setup(files schemaFiles, load func(l *schemaLoader)) error {
+
+	s.lock.Lock()
+	s.files, s.load = files, load
+	s.lock.Unlock()
+
+	return s.Reload()
+}
+
+//Setup loads the schema from files, or directories of schema files
+func (s *Completer) Setup(paths ...string) (err error) {
+	return s.SetupFS(nil, paths...)
+}
+
+//SetupFS loads the schema from files of fsys, e.g. an embed.FS, or from files on disk if fsys is nil
+func (s *Completer) SetupFS(fsys fs.FS, paths ...string) (err error) {
+
+	var files schemaFiles = diskFiles{}
+	if fsys != nil {
+		files = fsFiles{fsys}
+	}
+
+	paths = append([]string{}, paths...)
+
+	return s.setup(files, func(l *schemaLoader) {
+		for _, path := range paths {
+			l.loadPath(path)
+		}
+	})
+}
+
+//SetupBytes loads the schema from data, which can not include files
+func (s *Completer) SetupBytes(data []byte, format SchemaFormat) (err error) {
+
+	data = append([]byte{}, data...)
+
+	return s.setup(nil, func(l *schemaLoader) {
+		l.loadBytes(data, format, "")
+	})
+}
+
+//SetupReader loads the schema read from r, which can not include files
+func (s *Completer) SetupReader(r io.Reader, format SchemaFormat) (err error) {
+
+	data, err := ioutil.ReadAll(r)
 	if err != nil {
 		return
 	}
 
-	re := regexp.MustCompile(`(?m)^\s*//.*$`)
+	return s.SetupBytes(data, format)
+}
+
+//Reload loads the schema again the way it was set up. If the new schema has errors,
+//the one in use is kept and a *SchemaError is returned.
+func (s *Completer) Reload() (err error) {
 
-	err = json.Unmarshal(re.ReplaceAll(s.source, []byte{}), &s.schema)
+	s.lock.RLock()
+	files, load, noHelp := s.files, s.load, s.noHelp
+	s.lock.RUnlock()
+
+	loader := newSchemaLoader(files)
+
+	if load != nil {
+		load(loader)
+	}
 
 	//internal registered commands
-	s.schema.Commands = append(s.schema.Commands, cmdRegList...)
+	loader.schema.Commands = append(loader.schema.Commands, registeredCommands()...)
 
-	if err != nil {
-		return fmt.Errorf("completer config file load error, %s", err.Error())
+	if !noHelp && !loader.schema.takesKeyword(HelpCommand) {
+		loader.schema.Commands = append(loader.schema.Commands, helpCommand())
 	}
 
-	for i := range s.schema.Commands {
-		c := &s.schema.Commands[i]
-		for j := range c.Params {
-			pp := &c.Params[j]
+	problems := loader.finish(false)
 
-			if len(pp.Condition) == 0 {
-				c.staticParams = append(c.staticParams, pp)
-			} else {
-				c.dynamParams = append(c.dynamParams, pp)
-			}
-		}
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.watched = stringsUniq(loader.watched)
+
+	if hasErrors(problems) {
+		return &SchemaError{Problems: problems}
 	}
 
+	s.schema = &loader.schema
+
 	return
 }
 
-func (s *Completer) GetCompletes(input string) (completions []string) {
+//split a command line to complete or help. next tells if the last field is done, so the next one is the one
+//to complete, otherwise it is the last one. quote is the one the last field is left in, 0 if none.
+func completeFields(input string) (segs []string, next bool, quote byte) {
 
-	next := strings.HasSuffix(input, " ") //get "completions" of next param if input is end with space(s), otherwise, get "completions" of "this" param
-	segs := CmdlineField(input).Strings() //split input into segments. TODO: handle unclosed quots/brackets/...
+	fields := CmdlineField(input)
+	segs = fields.Strings()
 
-	for _, command := range s.schema.Commands {
-		//combine "completions" from each commands
-		completions = append(completions, command.complete(segs, next)...)
+	if len(segs) == 0 {
+		return segs, strings.HasSuffix(input, " "), 0
 	}
 
-	completions = stringsUniq(completions) //remove duplicated "completions"
+	return segs, fields.segs[len(segs)-1].end < len(input), fields.unclosed
+}
 
-	if commonPrefix := LongestCommonPrefix(completions); len(commonPrefix) != 0 {
-		return []string{commonPrefix} //found "loggest common prefix" and return it
-	}
+//a completion is not cut in an escape
+func trimEscape(completion string) string {
 
-	for _, comp := range completions {
-		if comp == " " {
-			return []string{comp} //found a "completion" which is a single space, return it
-		}
+	if trimmed := strings.TrimRight(completion, "\\"); (len(completion)-len(trimmed))%2 == 1 {
+		return completion[:len(completion)-1]
 	}
 
-	return
+	return completion
 }
 
 func (s *Completer) GetHelps(input string) (helpStr string) {
 
-	next := strings.HasSuffix(input, " ")
-	segs := CmdlineField(input).Strings() //split input into segments. TODO: handle unclosed quots/brackets/...
+	segs, next, _ := completeFields(input)
 
 	var helps []cmdHelp
+	schema := s.current()
 
-	for _, cmd := range s.schema.Commands {
+	for _, cmd := range schema.Commands {
+		if words, ok := cmd.helpWords(segs, next); ok && cmd.builtin {
+			helps = append(helps, schema.helpHelps(words, next)...)
+			continue
+		}
 		helps = append(helps, cmd.help(segs, next)...)
 	}
 
@@ -778,19 +767,12 @@ func (s *Completer) GetHelps(input string) (helpStr string) {
 		}
 	}
 
+	//the line can be executed as it is
+	if next && schema.executable(input) {
+		fmt.Fprintf(tw, "%s\t\n", "<cr>")
+	}
+
 	tw.Flush()
 
 	return buf.String()
 }
-
-//dynamic command insert
-var cmdRegList []schemaCommand
-
-func RegisterCmd(raw []byte) {
-	var cmd schemaCommand
-	re := regexp.MustCompile(`(?m)^\s*//.*$`)
-	if err := json.Unmarshal(re.ReplaceAll(raw, []byte{}), &cmd); err != nil {
-		return
-	}
-	cmdRegList = append(cmdRegList, cmd)
-}
@@ -0,0 +1,538 @@
+package completer
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+//Conditions of a param tell when it can be given. The conditions of the list are ANDed, each one is an expression:
+//
+//	expr       := term { "or" term }
+//	term       := factor { "and" factor }
+//	factor     := "not" factor | "(" expr ")" | "*" | "exists" "(" name ")" | comparison
+//	comparison := operand [ "not" ] op operand | operand [ "not" ] "in" word { word }
+//	op         := "eq" | "ne" | "==" | "!=" | "<" | "<=" | ">" | ">="
+//	operand    := "-" | "-N" | "{-}" | "{-N}" | "{name}" | "count" "(" name ")" | word
+//
+//"-N" is the name of the Nth param given before, "{-N}" its value, "-" and "{-}" stand for "-1" and "{-1}".
+//"{name}" is the values given to the param name, a comparison is true if any of them is.
+//Operands which are both numbers are compared by value, otherwise as strings.
+//A word may hold '=', '!', '<' and '>' after its first character, e.g. "{-} eq a=b". A word starting
+//with one of them, or holding a space, is quoted: "{-} eq '!x'".
+//
+//e.g. "- eq name", "{-2} in up down", "count(tag) < 3 and not exists(all)"
+
+type condExpr interface {
+	eval(context *cmdContext) bool
+}
+
+type condAny struct{}
+
+func (condAny) eval(context *cmdContext) bool {
+	return true
+}
+
+type condNot struct {
+	expr condExpr
+}
+
+func (c condNot) eval(context *cmdContext) bool {
+	return !c.expr.eval(context)
+}
+
+type condAnd []condExpr
+
+func (c condAnd) eval(context *cmdContext) bool {
+	for _, e := range c {
+		if !e.eval(context) {
+			return false
+		}
+	}
+	return true
+}
+
+type condOr []condExpr
+
+func (c condOr) eval(context *cmdContext) bool {
+	for _, e := range c {
+		if e.eval(context) {
+			return true
+		}
+	}
+	return false
+}
+
+type condExists struct {
+	name string
+}
+
+func (c condExists) eval(context *cmdContext) bool {
+	return context.count(c.name) > 0
+}
+
+type operandKind int
+
+const (
+	operandLiteral  operandKind = iota
+	operandRelName              //name of a param given before
+	operandRelValue             //value of a param given before
+	operandValue                //values of a named param
+	operandCount                //times a named param has been given
+)
+
+type condOperand struct {
+	kind   operandKind
+	name   string //the literal for operandLiteral
+	offset int
+}
+
+func (o condOperand) values(context *cmdContext) []string {
+
+	switch o.kind {
+	case operandRelName, operandRelValue:
+		name, value, ok := context.getBack(o.offset)
+		if !ok {
+			return nil
+		}
+		if o.kind == operandRelName {
+			return []string{name}
+		}
+		return []string{value}
+	case operandValue:
+		return context.lookup(o.name)
+	case operandCount:
+		return []string{strconv.Itoa(context.count(o.name))}
+	default:
+		return []string{o.name}
+	}
+}
+
+type condCompare struct {
+	left  condOperand
+	op    string
+	right []condOperand //more than one for "in"
+	not   bool
+}
+
+func compareValues(a, op, b string) bool {
+
+	//-1, 0 or 1 as a is less than, equal to or greater than b
+	order := strings.Compare(a, b)
+
+	x, errX := strconv.ParseFloat(a, 64)
+	y, errY := strconv.ParseFloat(b, 64)
+
+	if errX == nil && errY == nil {
+		switch {
+		case x < y:
+			order = -1
+		case x > y:
+			order = 1
+		default:
+			order = 0
+		}
+	}
+
+	switch op {
+	case "eq", "==":
+		return order == 0
+	case "ne", "!=":
+		return order != 0
+	case "<":
+		return order < 0
+	case "<=":
+		return order <= 0
+	case ">":
+		return order > 0
+	case ">=":
+		return order >= 0
+	}
+
+	return false
+}
+
+func (c condCompare) eval(context *cmdContext) bool {
+
+	op := c.op
+	if op == "in" {
+		op = "eq"
+	}
+
+	matched := false
+
+	for _, l := range c.left.values(context) {
+		for _, operand := range c.right {
+			for _, r := range operand.values(context) {
+				if compareValues(l, op, r) {
+					matched = true
+				}
+			}
+		}
+	}
+
+	return matched != c.not
+}
+
+type condition struct {
+	expr     condExpr
+	relative bool     //refers to the params given before, "-N" or "{-N}"
+	names    []string //param names referred to
+	literals []string //the values compared with
+}
+
+type condParser struct {
+	tokens   []string
+	pos      int
+	relative bool
+	names    []string
+	literals []string
+}
+
+//split a condition into tokens: brackets, commas, operators, "{...}", quoted strings and words
+func condTokens(str string) (tokens []string, err error) {
+
+	for i := 0; i < len(str); {
+
+		ch := str[i]
+
+		switch {
+		case ch == ' ' || ch == '\t':
+			i++
+		case ch == '(' || ch == ')' || ch == ',':
+			tokens = append(tokens, string(ch))
+			i++
+		case ch == '{':
+			end := strings.IndexByte(str[i:], '}')
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed '{'")
+			}
+			tokens = append(tokens, str[i:i+end+1])
+			i += end + 1
+		case ch == '"' || ch == '\'':
+			end := strings.IndexByte(str[i+1:], ch)
+			if end < 0 {
+				return nil, fmt.Errorf("unclosed %c", ch)
+			}
+			tokens = append(tokens, str[i:i+end+2])
+			i += end + 2
+		case strings.IndexByte("<>=!", ch) >= 0:
+			if i+1 < len(str) && str[i+1] == '=' {
+				tokens = append(tokens, str[i:i+2])
+				i += 2
+			} else if ch == '<' || ch == '>' {
+				tokens = append(tokens, str[i:i+1])
+				i++
+			} else {
+				return nil, fmt.Errorf("unknown operator '%c', a value starting with it is to be quoted", ch)
+			}
+		default:
+			j := i
+			for j < len(str) && strings.IndexByte(" \t(),{}\"'", str[j]) < 0 {
+				j++
+			}
+			tokens = append(tokens, str[i:j])
+			i = j
+		}
+	}
+
+	return
+}
+
+//compileCondition parses a condition of a param
+func compileCondition(str string) (cond *condition, err error) {
+
+	tokens, err := condTokens(str)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("empty condition")
+	}
+
+	p := &condParser{tokens: tokens}
+
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.pos != len(p.tokens) {
+		return nil, fmt.Errorf("unexpected '%s'", p.tokens[p.pos])
+	}
+
+	return &condition{expr: expr, relative: p.relative, names: p.names, literals: p.literals}, nil
+}
+
+func (p *condParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *condParser) next() string {
+	t := p.peek()
+	p.pos++
+	return t
+}
+
+func (p *condParser) expect(token string) error {
+	if t := p.peek(); t != token {
+		if t == "" {
+			return fmt.Errorf("'%s' expected at the end", token)
+		}
+		return fmt.Errorf("'%s' expected, got '%s'", token, t)
+	}
+	p.pos++
+	return nil
+}
+
+func (p *condParser) parseOr() (condExpr, error) {
+
+	var or condOr
+
+	for {
+		e, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		or = append(or, e)
+
+		if p.peek() != "or" {
+			break
+		}
+		p.pos++
+	}
+
+	if len(or) == 1 {
+		return or[0], nil
+	}
+	return or, nil
+}
+
+func (p *condParser) parseAnd() (condExpr, error) {
+
+	var and condAnd
+
+	for {
+		e, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		and = append(and, e)
+
+		if p.peek() != "and" {
+			break
+		}
+		p.pos++
+	}
+
+	if len(and) == 1 {
+		return and[0], nil
+	}
+	return and, nil
+}
+
+func (p *condParser) parseFactor() (condExpr, error) {
+
+	switch p.peek() {
+	case "":
+		return nil, fmt.Errorf("unexpected end")
+	case "not":
+		p.pos++
+		e, err := p.parseFactor()
+		if err != nil {
+			return nil, err
+		}
+		return condNot{e}, nil
+	case "(":
+		p.pos++
+		e, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		return e, p.expect(")")
+	case "*":
+		p.pos++
+		return condAny{}, nil
+	case "exists":
+		if p.pos+1 < len(p.tokens) && p.tokens[p.pos+1] == "(" {
+			p.pos += 2
+			name := p.next()
+			if !isCondWord(name) {
+				return nil, fmt.Errorf("param name expected in exists()")
+			}
+			p.names = append(p.names, name)
+			return condExists{name}, p.expect(")")
+		}
+	}
+
+	return p.parseCompare()
+}
+
+func isCondWord(token string) bool {
+	return token != "" && strings.IndexByte("(),{<>=!", token[0]) < 0
+}
+
+func isCondKeyword(token string) bool {
+	switch token {
+	case "and", "or", "not", "in", "eq", "ne":
+		return true
+	}
+	return false
+}
+
+func (p *condParser) parseCompare() (condExpr, error) {
+
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	c := condCompare{left: left}
+
+	if p.peek() == "not" {
+		c.not = true
+		p.pos++
+	}
+
+	c.op = p.next()
+
+	switch c.op {
+	case "in":
+		for isCondWord(p.peek()) && !isCondKeyword(p.peek()) || strings.HasPrefix(p.peek(), "{") {
+			r, err := p.parseOperand()
+			if err != nil {
+				return nil, err
+			}
+			c.right = append(c.right, r)
+		}
+		if len(c.right) == 0 {
+			return nil, fmt.Errorf("values expected after 'in'")
+		}
+	case "eq", "ne", "==", "!=", "<", "<=", ">", ">=":
+		r, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		c.right = []condOperand{r}
+	case "":
+		return nil, fmt.Errorf("operator expected at the end")
+	default:
+		return nil, fmt.Errorf("operator expected, got '%s'", c.op)
+	}
+
+	//"-N" is compared with param names, the others with values
+	for _, r := range c.right {
+		if r.kind != operandLiteral {
+			continue
+		}
+		if c.left.kind == operandRelName {
+			p.names = append(p.names, r.name)
+		} else {
+			p.literals = append(p.literals, r.name)
+		}
+	}
+
+	return c, nil
+}
+
+//parse "-N" or "N" of a relative reference, an empty string is 1
+func relOffset(str string) (offset int, err error) {
+
+	if str == "" {
+		return 1, nil
+	}
+
+	offset, err = strconv.Atoi(str)
+	if err != nil || offset < 1 {
+		return 0, fmt.Errorf("invalid offset '%s'", str)
+	}
+
+	return
+}
+
+func (p *condParser) parseOperand() (o condOperand, err error) {
+
+	t := p.next()
+
+	switch {
+	case t == "":
+		err = fmt.Errorf("operand expected at the end")
+	case t == "count":
+		if err = p.expect("("); err != nil {
+			return
+		}
+		o.kind, o.name = operandCount, p.next()
+		if !isCondWord(o.name) {
+			return o, fmt.Errorf("param name expected in count()")
+		}
+		p.names = append(p.names, o.name)
+		err = p.expect(")")
+	case strings.HasPrefix(t, "{"):
+		ref := strings.TrimSpace(t[1 : len(t)-1])
+		if strings.HasPrefix(ref, "-") {
+			o.kind = operandRelValue
+			o.offset, err = relOffset(ref[1:])
+			p.relative = true
+		} else if ref != "" {
+			o.kind, o.name = operandValue, ref
+			p.names = append(p.names, ref)
+		} else {
+			err = fmt.Errorf("empty '{}'")
+		}
+	case strings.HasPrefix(t, "-") && (t == "-" || t[1] >= '0' && t[1] <= '9'):
+		o.kind = operandRelName
+		o.offset, err = relOffset(t[1:])
+		p.relative = true
+	case t[0] == '"' || t[0] == '\'':
+		o.name = t[1 : len(t)-1]
+	case isCondWord(t):
+		o.name = t
+	default:
+		err = fmt.Errorf("operand expected, got '%s'", t)
+	}
+
+	return
+}
+
+//compile the conditions of the param
+func (p *schemaParam) compileConditions() error {
+
+	p.conditions = nil
+
+	for _, str := range p.Condition {
+		cond, err := compileCondition(str)
+		if err != nil {
+			return fmt.Errorf("condition \"%s\": %s", str, err.Error())
+		}
+		p.conditions = append(p.conditions, cond)
+	}
+
+	return nil
+}
+
+func (p *schemaParam) conditionCheck(context *cmdContext) bool {
+
+	for _, c := range p.conditions {
+		if !c.expr.eval(context) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//does a condition of the param check the params given right before it, like "- eq name"
+func (p *schemaParam) relativeCondition() bool {
+
+	for _, c := range p.conditions {
+		if c.relative {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,140 @@
+package completer
+
+import (
+	"strings"
+	"testing"
+)
+
+//the params given, "name=value" each
+func testContext(given ...string) (context *cmdContext) {
+
+	context = new(cmdContext)
+	context.init()
+
+	for _, g := range given {
+		i := strings.IndexByte(g, '=')
+		context.append(g[:i], g[i+1:])
+	}
+
+	return
+}
+
+func TestConditionEval(t *testing.T) {
+
+	tests := []struct {
+		condition string
+		given     []string
+		met       bool
+	}{
+		{"*", nil, true},
+		{"- eq name", []string{"name=eth0"}, true},
+		{"- eq name", []string{"name=eth0", "mode=access"}, false},
+		{"-2 eq name", []string{"name=eth0", "mode=access"}, true},
+		{"{-} eq access", []string{"mode=access"}, true},
+		{"{-} ne access", []string{"mode=access"}, false},
+		{"{-} not eq access", []string{"mode=trunk"}, true},
+		{"{-1} == access", []string{"mode=access"}, true},
+		{"{-} != access", []string{"mode=trunk"}, true},
+		{"{-2} in up down", []string{"state=down", "x=1"}, true},
+		{"{-2} not in up down", []string{"state=down", "x=1"}, false},
+		{"{mode} eq trunk", []string{"mode=trunk", "vlan=1"}, true},
+		{"{mode} eq trunk", nil, false},
+		{"{tag} eq b", []string{"tag=a", "tag=b"}, true},
+		{"count(tag) < 3", []string{"tag=a", "tag=b"}, true},
+		{"count(tag) < 3", []string{"tag=a", "tag=b", "tag=c"}, false},
+		{"count(tag)>=2", []string{"tag=a", "tag=b"}, true},
+		{"{speed} > 9", []string{"speed=10"}, true},
+		{"{speed} > 9", []string{"speed=8"}, false},
+		{"{name} < b", []string{"name=a"}, true},
+		{"exists(all)", []string{"all=all"}, true},
+		{"not exists(all)", nil, true},
+		{"count(tag) < 3 and not exists(all)", []string{"tag=a", "all=all"}, false},
+		{"exists(a) or exists(b)", []string{"b=1"}, true},
+		{"not (exists(a) or exists(b))", []string{"b=1"}, false},
+		{"exists(a) and exists(b) or exists(c)", []string{"c=1"}, true},
+		{`{-} eq "a b"`, []string{"x=a b"}, true},
+		{"{-} eq '!x'", []string{"x=!x"}, true},
+		//'=' and '!' in a word
+		{"{-} eq a=b", []string{"x=a=b"}, true},
+		{"{-} in x!y z", []string{"x=x!y"}, true},
+		{"{-} eq a>b", []string{"x=a>b"}, true},
+	}
+
+	for _, test := range tests {
+
+		cond, err := compileCondition(test.condition)
+		if err != nil {
+			t.Errorf("%q: %v", test.condition, err)
+			continue
+		}
+
+		if met := cond.expr.eval(testContext(test.given...)); met != test.met {
+			t.Errorf("%q with %q: %v, expected %v", test.condition, test.given, met, test.met)
+		}
+	}
+}
+
+func TestConditionCompile(t *testing.T) {
+
+	tests := []struct {
+		condition string
+		err       string //in the error, "" if none
+		relative  bool
+		names     []string
+	}{
+		{"- eq name", "", true, []string{"name"}},
+		{"{mode} eq trunk", "", false, []string{"mode"}},
+		{"count(tag) < 3 and not exists(all)", "", false, []string{"tag", "all"}},
+		{"", "empty condition", false, nil},
+		{"{-} eq", "operand expected at the end", false, nil},
+		{"{-}", "operator expected at the end", false, nil},
+		{"{-} like x", "operator expected, got 'like'", false, nil},
+		{"{-} in", "values expected after 'in'", false, nil},
+		{"(exists(a)", "')' expected at the end", false, nil},
+		{"exists(a))", "unexpected ')'", false, nil},
+		{"{mode eq x", "unclosed '{'", false, nil},
+		{`{-} eq "x`, `unclosed "`, false, nil},
+		{"{-} eq =x", "unknown operator '=', a value starting with it is to be quoted", false, nil},
+		{"{-} eq !x", "unknown operator '!'", false, nil},
+		{"-0 eq x", "invalid offset '0'", false, nil},
+		{"{} eq x", "empty '{}'", false, nil},
+		{"count(() < 1", "param name expected in count()", false, nil},
+	}
+
+	for _, test := range tests {
+
+		cond, err := compileCondition(test.condition)
+
+		if test.err != "" {
+			if err == nil || !strings.Contains(err.Error(), test.err) {
+				t.Errorf("%q: %v, expected %q", test.condition, err, test.err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%q: %v", test.condition, err)
+			continue
+		}
+
+		if cond.relative != test.relative || strings.Join(cond.names, ",") != strings.Join(test.names, ",") {
+			t.Errorf("%q: relative %v, names %q", test.condition, cond.relative, cond.names)
+		}
+	}
+}
+
+//a condition the baseline format took, with '=' or '!' in the value, still loads
+func TestConditionLoad(t *testing.T) {
+
+	c := newTestCompleter(t, `{"commands": [{"name": "env", "prefix": "env", "param": [
+		{"name": "kv", "type": "selection", "range": ["a=b", "c!d"]},
+		{"name": "extra", "type": "plain", "condition": ["{-} eq a=b"]}]}]}`)
+
+	if err := c.Validate("env a=b x"); err != nil {
+		t.Errorf("env a=b x: %v", err)
+	}
+
+	if err := c.Validate("env c!d x"); err == nil {
+		t.Errorf("env c!d x: validated")
+	}
+}
@@ -0,0 +1,286 @@
+package completer
+
+import (
+	"bufio"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+type DocFormat int
+
+const (
+	DocMarkdown DocFormat = iota
+	DocHTML               //a standalone page
+	DocMan                //a troff man page
+)
+
+func (f DocFormat) String() string {
+	switch f {
+	case DocMarkdown:
+		return "Markdown"
+	case DocHTML:
+		return "HTML"
+	case DocMan:
+		return "man"
+	default:
+		return fmt.Sprintf("DocFormat(%d)", int(f))
+	}
+}
+
+type docValue struct {
+	value string
+	help  string
+}
+
+type docParam struct {
+	name      string
+	help      string
+	kind      string
+	values    []docValue
+	optional  bool
+	unique    bool
+	condition string
+}
+
+type docCommand struct {
+	name     string
+	synopsis string
+	comment  string
+	params   []docParam
+}
+
+func (c *schemaCommand) doc() (doc docCommand) {
+
+	doc = docCommand{name: c.Name, synopsis: c.usage(), comment: c.Comment}
+
+	if doc.name == "" {
+		doc.name = strings.Join(strings.Fields(c.Prefix), " ")
+	}
+
+	for i := range c.Params {
+
+		p := &c.Params[i]
+
+		dp := docParam{
+			name:      p.NameDesc.Name,
+			help:      p.NameDesc.desc,
+			kind:      strings.ToLower(p.Type.String()),
+			optional:  p.Optional,
+			unique:    p.Unique,
+			condition: strings.Join(p.Condition, " and "),
+		}
+
+		switch p.Type {
+		case paramTypeSelection:
+			sels, descs, _ := rangeDecodeSelection(p.Range)
+			for j, s := range sels {
+				if s != "" {
+					dp.values = append(dp.values, docValue{value: s, help: descs[j]})
+				}
+			}
+		case paramTypeInteger:
+			if min, max, bounded, _ := rangeDecodeInteger(p.Range); bounded {
+				dp.values = []docValue{{value: fmt.Sprintf("%d-%d", min, max)}}
+			}
+		}
+
+		doc.params = append(doc.params, dp)
+	}
+
+	return
+}
+
+//when the param is given, in words
+func (p docParam) usage() string {
+
+	var words []string
+
+	conditional := p.condition != "" && p.condition != "*"
+
+	switch {
+	case p.optional:
+		words = append(words, "optional")
+	case conditional:
+		words = append(words, "required when "+p.condition)
+	default:
+		words = append(words, "required")
+	}
+
+	if p.unique {
+		words = append(words, "once at most")
+	}
+
+	if p.optional && conditional {
+		words = append(words, "when "+p.condition)
+	}
+
+	return strings.Join(words, ", ")
+}
+
+func (v docValue) String() string {
+	if v.help == "" {
+		return v.value
+	}
+	return v.value + ": " + v.help
+}
+
+//WriteDoc writes the reference of the commands of the schema, the built-in ones are left out
+func (s *Completer) WriteDoc(w io.Writer, format DocFormat, title string) error {
+
+	schema := s.current()
+
+	var docs []docCommand
+	for i := range schema.Commands {
+		if !schema.Commands[i].builtin {
+			docs = append(docs, schema.Commands[i].doc())
+		}
+	}
+
+	out := bufio.NewWriter(w)
+
+	switch format {
+	case DocMarkdown:
+		writeMarkdown(out, title, docs)
+	case DocHTML:
+		writeHTML(out, title, docs)
+	case DocMan:
+		writeMan(out, title, docs)
+	default:
+		return fmt.Errorf("unknown doc format %s", format)
+	}
+
+	return out.Flush()
+}
+
+func markdownCell(str string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(str)
+}
+
+func writeMarkdown(w io.Writer, title string, docs []docCommand) {
+
+	fmt.Fprintf(w, "# %s\n", title)
+
+	for _, d := range docs {
+
+		fmt.Fprintf(w, "\n## %s\n\n", d.name)
+		fmt.Fprintf(w, "```\n%s\n```\n", d.synopsis)
+
+		if d.comment != "" {
+			fmt.Fprintf(w, "\n%s\n", d.comment)
+		}
+
+		if len(d.params) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "\n| Param | Type | Values | Usage | Description |\n|---|---|---|---|---|\n")
+
+		for _, p := range d.params {
+
+			var values []string
+			for _, v := range p.values {
+				values = append(values, "`"+markdownCell(v.value)+"`"+markdownCell(strings.TrimPrefix(v.String(), v.value)))
+			}
+
+			fmt.Fprintf(w, "| %s | %s | %s | %s | %s |\n",
+				markdownCell(p.name), p.kind, strings.Join(values, "<br>"), markdownCell(p.usage()), markdownCell(p.help))
+		}
+	}
+}
+
+func writeHTML(w io.Writer, title string, docs []docCommand) {
+
+	esc := html.EscapeString
+
+	fmt.Fprintf(w, `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 60em; margin: auto; }
+pre { background: #f4f4f4; padding: 0.5em; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+</style>
+</head>
+<body>
+<h1>%s</h1>
+`, esc(title), esc(title))
+
+	for _, d := range docs {
+
+		fmt.Fprintf(w, "<h2>%s</h2>\n<pre>%s</pre>\n", esc(d.name), esc(d.synopsis))
+
+		if d.comment != "" {
+			fmt.Fprintf(w, "<p>%s</p>\n", esc(d.comment))
+		}
+
+		if len(d.params) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(w, "<table>\n<tr><th>Param</th><th>Type</th><th>Values</th><th>Usage</th><th>Description</th></tr>\n")
+
+		for _, p := range d.params {
+
+			var values []string
+			for _, v := range p.values {
+				values = append(values, "<code>"+esc(v.value)+"</code>"+esc(strings.TrimPrefix(v.String(), v.value)))
+			}
+
+			fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+				esc(p.name), p.kind, strings.Join(values, "<br>"), esc(p.usage()), esc(p.help))
+		}
+
+		fmt.Fprintf(w, "</table>\n")
+	}
+
+	fmt.Fprintf(w, "</body>\n</html>\n")
+}
+
+//escape a text for troff, lines starting with a control character are kept as text
+func manText(str string) string {
+
+	str = strings.NewReplacer("\\", "\\e", "-", "\\-", "\n", " ").Replace(str)
+
+	if strings.HasPrefix(str, ".") || strings.HasPrefix(str, "'") {
+		str = "\\&" + str
+	}
+
+	return str
+}
+
+func writeMan(w io.Writer, title string, docs []docCommand) {
+
+	fmt.Fprintf(w, ".TH \"%s\" 7\n", strings.ReplaceAll(manText(strings.ToUpper(title)), "\"", "\\(dq"))
+	fmt.Fprintf(w, ".SH NAME\n%s\n", manText(title))
+	fmt.Fprintf(w, ".SH COMMANDS\n")
+
+	for _, d := range docs {
+
+		fmt.Fprintf(w, ".SS %s\n", manText(d.name))
+		fmt.Fprintf(w, ".nf\n.B %s\n.fi\n", manText(d.synopsis))
+
+		if d.comment != "" {
+			fmt.Fprintf(w, ".PP\n%s\n", manText(d.comment))
+		}
+
+		for _, p := range d.params {
+
+			fmt.Fprintf(w, ".TP\n.B %s\n", manText(p.name))
+
+			text := p.kind + ", " + p.usage() + "."
+			if p.help != "" {
+				text += " " + p.help
+			}
+			fmt.Fprintf(w, "%s\n", manText(text))
+
+			for _, v := range p.values {
+				fmt.Fprintf(w, ".br\n%s\n", manText(v.String()))
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package completer
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDocParamUsage(t *testing.T) {
+
+	tests := []struct {
+		param docParam
+		usage string
+	}{
+		{docParam{}, "required"},
+		{docParam{optional: true}, "optional"},
+		{docParam{unique: true}, "required, once at most"},
+		{docParam{condition: "*"}, "required"},
+		{docParam{condition: "*", optional: true, unique: true}, "optional, once at most"},
+		{docParam{condition: "{-} eq access", unique: true}, "required when {-} eq access, once at most"},
+		{docParam{condition: "- eq name", optional: true}, "optional, when - eq name"},
+	}
+
+	for _, test := range tests {
+		if usage := test.param.usage(); usage != test.usage {
+			t.Errorf("%+v: %q, expected %q", test.param, usage, test.usage)
+		}
+	}
+}
+
+func TestWriteDoc(t *testing.T) {
+
+	tests := []struct {
+		schema string
+		format DocFormat
+		want   []string
+		absent []string
+	}{
+		{testSchema, DocMarkdown, []string{
+			"# Commands\n",
+			"## show interface\n\n```\nshow interface <name> [brief|detail]\n```\n\nShow interfaces\n",
+			"| name | plain |  | required | interface name |",
+			"| detail | selection | `brief`: brief info<br>`detail`: detailed info | optional, when - eq name |  |",
+			"| tag | plain |  | optional, once at most |  |",
+		}, nil},
+		{conditionalSchema, DocMarkdown, []string{
+			"| vlan | integer | `1-4094` | required when {-} eq access, once at most |  |",
+			"| allowed | plain |  | required when {mode} eq trunk |  |",
+		}, []string{"optional, once at most, when"}},
+		{testSchema, DocHTML, []string{
+			"<title>Commands</title>",
+			"<h2>show interface</h2>\n<pre>show interface &lt;name&gt; [brief|detail]</pre>",
+			"<tr><td>detail</td><td>selection</td><td><code>brief</code>: brief info<br><code>detail</code>: detailed info</td>",
+		}, nil},
+		{testSchema, DocMan, []string{
+			".TH \"COMMANDS\" 7\n",
+			".SS show interface\n.nf\n.B show interface <name> [brief|detail]\n.fi\n",
+			"plain, required. interface name\n",
+		}, nil},
+	}
+
+	for _, test := range tests {
+
+		c := newTestCompleter(t, test.schema)
+
+		var out strings.Builder
+		if err := c.WriteDoc(&out, test.format, "Commands"); err != nil {
+			t.Fatal(err)
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s without %q:\n%s", test.format, want, out.String())
+			}
+		}
+
+		for _, absent := range test.absent {
+			if strings.Contains(out.String(), absent) {
+				t.Errorf("%s with %q:\n%s", test.format, absent, out.String())
+			}
+		}
+	}
+}
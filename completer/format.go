@@ -0,0 +1,104 @@
+package completer
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+//SchemaVersion is the latest version of the schema format
+const SchemaVersion = 1
+
+//JSONSchema describes the schema format, for editors to validate and complete schema files
+//
+//go:embed cli-ui.schema.json
+var JSONSchema []byte
+
+type SchemaFormat int
+
+const (
+	FormatJSON SchemaFormat = iota //JSON with "//" comment lines
+	FormatYAML
+	FormatTOML
+)
+
+func (f SchemaFormat) String() string {
+	switch f {
+	case FormatJSON:
+		return "JSON"
+	case FormatYAML:
+		return "YAML"
+	case FormatTOML:
+		return "TOML"
+	default:
+		return fmt.Sprintf("SchemaFormat(%d)", int(f))
+	}
+}
+
+//FormatOf tells the format of a schema file by its extension, JSON if unknown
+func FormatOf(filePath string) SchemaFormat {
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	default:
+		return FormatJSON
+	}
+}
+
+//yaml decodes the mappings with keys other than strings to map[interface{}]interface{}, which JSON can not take
+func jsonCompatible(v interface{}) interface{} {
+
+	switch t := v.(type) {
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(t))
+		for k, e := range t {
+			m[fmt.Sprint(k)] = jsonCompatible(e)
+		}
+		return m
+	case map[string]interface{}:
+		for k, e := range t {
+			t[k] = jsonCompatible(e)
+		}
+	case []interface{}:
+		for i, e := range t {
+			t[i] = jsonCompatible(e)
+		}
+	}
+
+	return v
+}
+
+//decode a schema, YAML and TOML are translated to JSON first
+func decodeSchema(source []byte, format SchemaFormat, schema *schemaTop) (err error) {
+
+	var generic interface{}
+
+	switch format {
+	case FormatYAML:
+		err = yaml.Unmarshal(source, &generic)
+	case FormatTOML:
+		var table map[string]interface{}
+		err = toml.Unmarshal(source, &table)
+		generic = table
+	default:
+		return json.Unmarshal(schemaComment.ReplaceAll(source, []byte{}), schema)
+	}
+
+	if err != nil {
+		return
+	}
+
+	if source, err = json.Marshal(jsonCompatible(generic)); err != nil {
+		return
+	}
+
+	return json.Unmarshal(source, schema)
+}
@@ -0,0 +1,174 @@
+package completer
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//the same schema in each format
+var formatSchemas = map[SchemaFormat]string{
+	FormatJSON: `// a comment line
+{
+  "version": 1,
+  "params": {"ifname": {"name": "ifname: interface name", "type": "plain"}},
+  "commands": [
+    // another one
+    {"name": "show interface", "prefix": "show interface", "comment": "Show interfaces",
+     "param": [
+       {"ref": "ifname"},
+       {"name": "detail", "type": "selection", "range": ["brief", "detail"], "condition": ["- eq ifname"], "optional": true}
+     ]},
+    {"name": "show vlan", "prefix": "show vlan", "param": [{"name": "id", "type": "integer", "range": "1-4094"}]}
+  ]
+}`,
+	FormatYAML: `version: 1
+params:
+  ifname: {name: "ifname: interface name", type: plain}
+commands:
+  - name: show interface
+    prefix: show interface
+    comment: Show interfaces
+    param:
+      - ref: ifname
+      - name: detail
+        type: selection
+        range: [brief, detail]
+        condition: ["- eq ifname"]
+        optional: true
+  - name: show vlan
+    prefix: show vlan
+    param:
+      - {name: id, type: integer, range: 1-4094}
+`,
+	FormatTOML: `version = 1
+
+[params.ifname]
+name = "ifname: interface name"
+type = "plain"
+
+[[commands]]
+name = "show interface"
+prefix = "show interface"
+comment = "Show interfaces"
+
+  [[commands.param]]
+  ref = "ifname"
+
+  [[commands.param]]
+  name = "detail"
+  type = "selection"
+  range = ["brief", "detail"]
+  condition = ["- eq ifname"]
+  optional = true
+
+[[commands]]
+name = "show vlan"
+prefix = "show vlan"
+
+  [[commands.param]]
+  name = "id"
+  type = "integer"
+  range = "1-4094"
+`,
+}
+
+func TestDecodeSchema(t *testing.T) {
+
+	var expected schemaTop
+	if err := decodeSchema([]byte(formatSchemas[FormatJSON]), FormatJSON, &expected); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(expected.Commands) != 2 || expected.Commands[0].Params[1].NameDesc.Name != "detail" {
+		t.Fatalf("decoded to %+v", expected)
+	}
+
+	for _, format := range []SchemaFormat{FormatYAML, FormatTOML} {
+
+		var decoded schemaTop
+		if err := decodeSchema([]byte(formatSchemas[format]), format, &decoded); err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(decoded, expected) {
+			t.Errorf("%s: decoded to %+v, expected %+v", format, decoded, expected)
+		}
+	}
+}
+
+func TestSetupFormats(t *testing.T) {
+
+	for format, schema := range formatSchemas {
+
+		c := new(Completer)
+		if err := c.SetupBytes([]byte(schema), format); err != nil {
+			t.Errorf("%s: %v", format, err)
+			continue
+		}
+
+		for _, input := range []string{"show interface eth0 brief", "sh vl 10"} {
+			if err := c.Validate(input); err != nil {
+				t.Errorf("%s: %q: %v", format, input, err)
+			}
+		}
+	}
+
+	for format, schema := range map[SchemaFormat]string{
+		FormatJSON: `{"commands": [`,
+		FormatYAML: "commands:\n\t- a",
+		FormatTOML: "commands = [",
+	} {
+		if err := new(Completer).SetupBytes([]byte(schema), format); err == nil {
+			t.Errorf("%s: %q set up", format, schema)
+		}
+	}
+}
+
+func TestSchemaVersion(t *testing.T) {
+
+	for _, version := range []int{0, SchemaVersion} {
+		schema := fmt.Sprintf(`{"version": %d, "commands": [{"name": "a", "prefix": "a"}]}`, version)
+		if err := new(Completer).SetupBytes([]byte(schema), FormatJSON); err != nil {
+			t.Errorf("version %d: %v", version, err)
+		}
+	}
+
+	err := new(Completer).SetupBytes([]byte(`{"version": 99}`), FormatJSON)
+	if err == nil || !strings.Contains(err.Error(), "version 99 is not supported") {
+		t.Errorf("version 99: %v", err)
+	}
+}
+
+func TestFormatOf(t *testing.T) {
+
+	tests := map[string]SchemaFormat{
+		"a.json":      FormatJSON,
+		"dir/a.yaml":  FormatYAML,
+		"a.YML":       FormatYAML,
+		"a.toml":      FormatTOML,
+		"a":           FormatJSON,
+		"a.toml.json": FormatJSON,
+	}
+
+	for path, format := range tests {
+		if got := FormatOf(path); got != format {
+			t.Errorf("%s: %s, expected %s", path, got, format)
+		}
+	}
+}
+
+func TestJSONSchema(t *testing.T) {
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(JSONSchema, &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, exist := schema["properties"]; !exist {
+		t.Errorf("no properties in the JSON Schema")
+	}
+}
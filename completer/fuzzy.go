@@ -0,0 +1,254 @@
+package completer
+
+import (
+	"sort"
+	"strings"
+)
+
+//MatchMode is how a field being given is matched against the keywords and the values of selections to complete it
+type MatchMode int
+
+const (
+	MatchPrefix MatchMode = iota //the ones the field is a prefix of, case-insensitively
+	MatchFuzzy                   //if there is none, also the ones the field is a subsequence or a typo of
+)
+
+//SetMatchMode changes how fields are completed, MatchPrefix by default
+func (s *Completer) SetMatchMode(mode MatchMode) {
+	s.lock.Lock()
+	s.mode = mode
+	s.lock.Unlock()
+}
+
+func (s *Completer) matchMode() MatchMode {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.mode
+}
+
+//how many of the command lines executed last are counted in ranking the completions
+const recentLines = 200
+
+//wordUsage counts the words of the command lines executed last
+type wordUsage struct {
+	lines  [][]string
+	counts map[string]int
+}
+
+func (u *wordUsage) record(words []string) {
+
+	if u.counts == nil {
+		u.counts = make(map[string]int)
+	}
+
+	if len(u.lines) == recentLines {
+		for _, w := range u.lines[0] {
+			if u.counts[w]--; u.counts[w] == 0 {
+				delete(u.counts, w)
+			}
+		}
+		u.lines = u.lines[1:]
+	}
+
+	u.lines = append(u.lines, words)
+
+	for _, w := range words {
+		u.counts[w]++
+	}
+}
+
+//Record counts the words of a command line executed, the completions used more often recently are ranked first
+func (s *Completer) Record(line string) {
+
+	var words []string
+	for _, w := range CmdlineField(line).Strings() {
+		words = append(words, strings.ToLower(w))
+	}
+
+	s.lock.Lock()
+	s.usage.record(words)
+	s.lock.Unlock()
+}
+
+//how often the word has been used recently
+func (s *Completer) frequency(word string) int {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	return s.usage.counts[strings.ToLower(word)]
+}
+
+//how a word is matched by a field, the lower the better
+const (
+	fuzzyPrefix      = iota //the field is a prefix of the word
+	fuzzySubsequence        //the runes of the field are in the word in order, from its first one
+	fuzzyTypo               //the field is a prefix of the word with a rune or two mistyped, left out, added or swapped
+)
+
+type fuzzyMatch struct {
+	word  string
+	help  cmdHelp
+	tier  int
+	score int //in the tier, the lower the better
+	freq  int
+}
+
+//edit distance of a and b, a swap of two runes next to each other is one edit
+func editDistance(a, b []rune) int {
+
+	d := make([][]int, len(a)+1)
+	for i := range d {
+		d[i] = make([]int, len(b)+1)
+		d[i][0] = i
+	}
+	for j := range d[0] {
+		d[0][j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			d[i][j] = min3(d[i-1][j]+1, d[i][j-1]+1, d[i-1][j-1]+cost)
+
+			if i > 1 && j > 1 && a[i-1] == b[j-2] && a[i-2] == b[j-1] && d[i-2][j-2]+1 < d[i][j] {
+				d[i][j] = d[i-2][j-2] + 1
+			}
+		}
+	}
+
+	return d[len(a)][len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+//how the field typed matches the word, ok is false if it does not
+func fuzzyScore(typed string, word string) (tier int, score int, ok bool) {
+
+	t, w := []rune(strings.ToLower(typed)), []rune(strings.ToLower(word))
+
+	if len(t) == 0 || len(w) == 0 {
+		return
+	}
+
+	if strings.HasPrefix(string(w), string(t)) {
+		return fuzzyPrefix, len(w) - len(t), true
+	}
+
+	//a subsequence, the score is how spread it is
+	if t[0] == w[0] {
+		i, last := 0, 0
+		for j := 0; j < len(w) && i < len(t); j++ {
+			if w[j] == t[i] {
+				i, last = i+1, j
+			}
+		}
+		if i == len(t) {
+			return fuzzySubsequence, last + 1 - len(t), true
+		}
+	}
+
+	if len(t) < 3 {
+		return
+	}
+
+	allowed := 1
+	if len(t) > 5 {
+		allowed = 2
+	}
+
+	//the prefixes of the word about as long as the field
+	best := allowed + 1
+	for n := len(t) - 1; n <= len(t)+1; n++ {
+		if n > 0 && n <= len(w) {
+			if d := editDistance(t, w[:n]); d < best {
+				best = d
+			}
+		}
+	}
+
+	if best <= allowed {
+		return fuzzyTypo, best, true
+	}
+
+	return
+}
+
+//the keywords and the values of selections the field typed matches, best first: by the way they are matched,
+//then the recent use, then the names
+func (s *Completer) fuzzyRank(typed string, helps []cmdHelp) (ranked []fuzzyMatch) {
+
+	seen := make(map[string]bool)
+
+	for _, h := range helps {
+
+		if h.kind == CandidateArgument || seen[h.whatToInput] {
+			continue
+		}
+		seen[h.whatToInput] = true
+
+		if tier, score, ok := fuzzyScore(typed, h.whatToInput); ok {
+			ranked = append(ranked, fuzzyMatch{word: h.whatToInput, help: h, tier: tier, score: score, freq: s.frequency(h.whatToInput)})
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := ranked[i], ranked[j]
+		switch {
+		case a.tier != b.tier:
+			return a.tier < b.tier
+		case a.score != b.score:
+			return a.score < b.score
+		case a.freq != b.freq:
+			return a.freq > b.freq
+		default:
+			return a.word < b.word
+		}
+	})
+
+	return
+}
+
+//rank the candidates by how often the words they complete to have been used recently, the order is kept otherwise
+func (s *Completer) rankCandidates(candidates []Candidate) {
+
+	if len(candidates) < 2 {
+		return
+	}
+
+	freqs := make(map[string]int)
+	for _, c := range candidates {
+		freqs[c.Value] = s.frequency(c.Value)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return freqs[candidates[i].Value] > freqs[candidates[j].Value]
+	})
+}
+
+//the keywords or the values the token could have been meant as, among the ones expected
+func (s *Completer) didYouMean(token string, expected []cmdHelp) (suggestions []string) {
+
+	ranked := s.fuzzyRank(token, expected)
+
+	//the best ones only
+	for _, m := range ranked {
+		if m.tier != ranked[0].tier || m.score != ranked[0].score || len(suggestions) == 3 {
+			break
+		}
+		suggestions = append(suggestions, m.word)
+	}
+
+	return
+}
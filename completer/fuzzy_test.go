@@ -0,0 +1,221 @@
+package completer
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEditDistance(t *testing.T) {
+
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"abc", "abc", 0},
+		{"abc", "abd", 1},
+		{"abc", "ab", 1},
+		{"abc", "abxc", 1},
+		{"abc", "acb", 1}, //a swap is one edit
+		{"intrface", "interface", 1},
+		{"kitten", "sitting", 3},
+		{"été", "ete", 2},
+	}
+
+	for _, test := range tests {
+		if d := editDistance([]rune(test.a), []rune(test.b)); d != test.distance {
+			t.Errorf("%q %q: %d, expected %d", test.a, test.b, d, test.distance)
+		}
+	}
+}
+
+func TestFuzzyScore(t *testing.T) {
+
+	tests := []struct {
+		typed, word string
+		tier, score int
+		ok          bool
+	}{
+		{"sh", "show", fuzzyPrefix, 2, true},
+		{"SH", "Show", fuzzyPrefix, 2, true},
+		{"show", "show", fuzzyPrefix, 0, true},
+		{"shw", "show", fuzzySubsequence, 1, true},
+		{"shw", "shutdown", fuzzySubsequence, 4, true},
+		{"intf", "interface", fuzzySubsequence, 2, true},
+		{"intrface", "interface", fuzzySubsequence, 1, true},
+		{"inrerface", "interface", fuzzyTypo, 1, true},
+		{"sohw", "show", fuzzyTypo, 1, true},
+		{"interfcae", "interface", fuzzyTypo, 1, true},
+		{"inetrfcae", "interface", fuzzyTypo, 2, true}, //two edits are allowed in a field of more than 5 runes
+		{"vaal", "vlan", 0, 0, false},                  //only one in a shorter one
+		{"hw", "show", 0, 0, false},                    //a subsequence from the first rune only
+		{"sj", "show", 0, 0, false},                    //no typo in a field of less than 3 runes
+		{"", "show", 0, 0, false},
+		{"show", "", 0, 0, false},
+	}
+
+	for _, test := range tests {
+		tier, score, ok := fuzzyScore(test.typed, test.word)
+		if ok != test.ok || ok && (tier != test.tier || score != test.score) {
+			t.Errorf("%q %q: tier %d, score %d, %v, expected tier %d, score %d, %v", test.typed, test.word,
+				tier, score, ok, test.tier, test.score, test.ok)
+		}
+	}
+}
+
+func TestFuzzyRank(t *testing.T) {
+
+	c := new(Completer)
+
+	var helps []cmdHelp
+	for _, w := range []string{"stop", "shutdown", "step", "show", "set", "stop"} {
+		helps = append(helps, cmdHelp{whatToInput: w, kind: CandidateKeyword})
+	}
+	helps = append(helps, cmdHelp{whatToInput: "<name>", kind: CandidateArgument})
+
+	rank := func(typed string) (words []string) {
+		for _, m := range c.fuzzyRank(typed, helps) {
+			words = append(words, m.word)
+		}
+		return
+	}
+
+	//by tier, then by score, then by name
+	if words := rank("s"); !reflect.DeepEqual(words, []string{"set", "show", "step", "stop", "shutdown"}) {
+		t.Errorf("s: %q", words)
+	}
+
+	if words := rank("shw"); !reflect.DeepEqual(words, []string{"show", "shutdown"}) {
+		t.Errorf("shw: %q", words)
+	}
+
+	//then by the recent use
+	c.Record("stop now")
+	c.Record(`"STOP" again`)
+
+	if words := rank("s"); !reflect.DeepEqual(words, []string{"set", "stop", "show", "step", "shutdown"}) {
+		t.Errorf("s, stop used: %q", words)
+	}
+
+	if suggestions := c.didYouMean("stp", helps); !reflect.DeepEqual(suggestions, []string{"stop", "step"}) {
+		t.Errorf("stp: suggested %q", suggestions)
+	}
+}
+
+//only the command lines executed last are counted
+func TestWordUsage(t *testing.T) {
+
+	c := new(Completer)
+
+	c.Record("first line")
+	for i := 0; i < recentLines-1; i++ {
+		c.Record(fmt.Sprintf("line %d", i))
+	}
+
+	if c.frequency("first") != 1 || c.frequency("LINE") != recentLines {
+		t.Errorf("first %d, line %d", c.frequency("first"), c.frequency("line"))
+	}
+
+	c.Record("last")
+
+	if c.frequency("first") != 0 || c.frequency("line") != recentLines-1 || c.frequency("last") != 1 {
+		t.Errorf("first %d, line %d, last %d", c.frequency("first"), c.frequency("line"), c.frequency("last"))
+	}
+
+	if _, counted := c.usage.counts["first"]; counted {
+		t.Errorf("a word no longer used is counted")
+	}
+}
+
+func TestValidateDidYouMean(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	tests := []struct {
+		input       string
+		suggestions []string
+	}{
+		{"shw", []string{"show"}},
+		{"shutdwn", []string{"shutdown"}},
+		{"show intrface eth0", []string{"interface"}},
+		{"show valn 1", []string{"vlan"}},
+		{"show interface eth0 brif", []string{"brief"}},
+		{"xyz", nil},
+	}
+
+	for _, test := range tests {
+
+		syntaxErr, ok := c.Validate(test.input).(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: validated", test.input)
+			continue
+		}
+
+		if !reflect.DeepEqual(syntaxErr.Suggestions, test.suggestions) {
+			t.Errorf("%q: suggested %q, expected %q", test.input, syntaxErr.Suggestions, test.suggestions)
+		}
+	}
+
+	err := c.Validate("shw")
+	if expected := "% Invalid input detected at '^' marker.\n% Expected: help, set, show, shutdown\n% Did you mean: show?"; err.Error() != expected {
+		t.Errorf("shw: %q, expected %q", err.Error(), expected)
+	}
+}
+
+func TestCompleteFuzzy(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	complete := func(input string) (values []string, replace int) {
+		candidates, replace := c.Complete(input)
+		for _, candidate := range candidates {
+			values = append(values, candidate.Value)
+		}
+		return
+	}
+
+	//by prefix only by default
+	if values, _ := complete("shw"); len(values) != 0 {
+		t.Errorf("shw: completed with %q by prefix", values)
+	}
+
+	c.SetMatchMode(MatchFuzzy)
+
+	tests := []struct {
+		input   string
+		values  []string
+		replace int
+	}{
+		{"shw", []string{"show", "shutdown"}, 3},
+		{"show intrface", []string{"interface"}, 8},
+		{"set stus", []string{"status"}, 4},
+		{"set stx", []string{"status"}, 3},
+		//the ones found by prefix come first, they are added to the line
+		{"show i", []string{"interface"}, 0},
+		{"shw ", nil, 0},
+		{`show "intrface`, nil, 0},
+		{"xyz", nil, 0},
+	}
+
+	for _, test := range tests {
+		if values, replace := complete(test.input); !reflect.DeepEqual(values, test.values) || replace != test.replace {
+			t.Errorf("%q: completed with %q replacing %d, expected %q replacing %d", test.input, values, replace, test.values, test.replace)
+		}
+	}
+
+	candidates, _ := c.Complete("shw")
+	if candidates[0].Text != "show " || candidates[0].Kind != CandidateKeyword {
+		t.Errorf("shw: %+v", candidates[0])
+	}
+
+	//the values used more often recently first
+	c.Record("set status 1")
+	c.Record("set status 2")
+	c.Record("set description 2")
+
+	if values, _ := complete("set "); !reflect.DeepEqual(values, []string{"status", "description", "speed"}) {
+		t.Errorf("set: completed with %q", values)
+	}
+}
@@ -0,0 +1,380 @@
+package completer
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+type GraphFormat int
+
+const (
+	GraphDOT     GraphFormat = iota //Graphviz
+	GraphMermaid                    //a Mermaid flowchart
+)
+
+func (f GraphFormat) String() string {
+	switch f {
+	case GraphDOT:
+		return "DOT"
+	case GraphMermaid:
+		return "Mermaid"
+	default:
+		return fmt.Sprintf("GraphFormat(%d)", int(f))
+	}
+}
+
+//the nodes of a param graph besides the params, which are the indexes of them in the command
+const graphStart = -1
+
+//graphEdge tells that the param "to" may follow the param "from"
+type graphEdge struct {
+	from int
+	to   int
+}
+
+//paramGraph is how the params of a command follow each other, the command prefix first and the
+//end of the command line last. It is found by trying the command lines the command takes.
+type paramGraph struct {
+	command *schemaCommand
+	end     int //the node of the end of the command line
+	edges   []graphEdge
+	values  map[graphEdge][]string //the values of the "from" param the edge is taken with
+	tried   map[int][]string       //the values each param is tried with
+	reached map[int]bool
+}
+
+//limit of the command lines tried in looking for the graph
+const reachLimit = 2000
+
+//find the graph of the params of the command. The plain and integer params are given the values
+//the conditions compare with, and the numbers next to them.
+func (c *schemaCommand) paramGraph() (g *paramGraph) {
+
+	g = &paramGraph{
+		command: c,
+		end:     len(c.Params),
+		values:  make(map[graphEdge][]string),
+		tried:   make(map[int][]string),
+		reached: make(map[int]bool),
+	}
+
+	index := make(map[*schemaParam]int)
+	for j := range c.Params {
+		index[&c.Params[j]] = j
+	}
+
+	var literals []string
+	for _, p := range c.dynamParams {
+		for _, cond := range p.conditions {
+			for _, l := range cond.literals {
+				literals = append(literals, l)
+				//the numbers around it for the comparisons
+				if n, err := strconv.ParseFloat(l, 64); err == nil {
+					literals = append(literals, strconv.FormatFloat(n-1, 'g', -1, 64), strconv.FormatFloat(n+1, 'g', -1, 64))
+				}
+			}
+		}
+	}
+	literals = stringsUniq(append(literals, "0"))
+
+	prefixLen := len(strings.Fields(c.Prefix))
+	visited := make(map[string]bool)
+
+	root := &cmdMatch{command: c, context: new(cmdContext), words: strings.Fields(c.Prefix)}
+	root.params = make([]*schemaParam, len(root.words))
+	root.context.init()
+
+	queue := []*cmdMatch{root}
+	tried := 0
+
+	for len(queue) != 0 && tried < reachLimit {
+
+		m := queue[0]
+		queue = queue[1:]
+
+		from, value := graphStart, ""
+		if m.param != nil {
+			from = index[m.param]
+			_, value, _ = m.context.getLast()
+		}
+
+		if m.complete() {
+			g.addEdge(graphEdge{from, g.end}, value)
+		}
+
+		//a param repeated without end
+		if len(m.words)-prefixLen > 2*len(c.Params)+1 {
+			continue
+		}
+
+		for _, next := range c.nextParams(m.param, m.staticParamPos, m.context) {
+
+			g.addEdge(graphEdge{from, index[next.param]}, value)
+
+			values, _, _ := rangeDecodeSelection(next.param.Range)
+
+			switch next.param.Type {
+			case paramTypePlain:
+				values = literals
+			case paramTypeInteger:
+				values = nil
+				if min, _, bounded, _ := rangeDecodeInteger(next.param.Range); bounded {
+					values = append(values, strconv.FormatInt(min, 10))
+				}
+				for _, l := range literals {
+					if next.param.checkValue(l) {
+						values = append(values, l)
+					}
+				}
+			}
+
+			for _, v := range values {
+				extended := m.extend(next, v, rankKeyword)
+				key := fmt.Sprintf("%p %d\n%s", extended.param, extended.staticParamPos, extended.context.String())
+				if visited[key] {
+					continue
+				}
+				visited[key] = true
+				tried++
+				queue = append(queue, extended)
+			}
+		}
+	}
+
+	return
+}
+
+func (g *paramGraph) addEdge(e graphEdge, value string) {
+
+	values, exist := g.values[e]
+	if !exist {
+		g.edges = append(g.edges, e)
+	}
+
+	g.values[e] = stringsUniqOrdered(append(values, value))
+	g.tried[e.from] = stringsUniqOrdered(append(g.tried[e.from], value))
+	g.reached[e.to] = true
+}
+
+//the values of a selection an edge is taken with, "" if it is taken with any
+func (g *paramGraph) edgeLabel(e graphEdge) string {
+
+	if e.from == graphStart || g.command.Params[e.from].Type != paramTypeSelection {
+		return ""
+	}
+
+	if values := g.values[e]; len(values) < len(g.tried[e.from]) {
+		return strings.Join(values, "|")
+	}
+
+	return ""
+}
+
+//find the params that can never be given
+func (c *schemaCommand) unreachableParams() (unreachable []int) {
+
+	g := c.paramGraph()
+
+	for j := range c.Params {
+		if !g.reached[j] {
+			unreachable = append(unreachable, j)
+		}
+	}
+
+	return
+}
+
+//the lines of the label of a node
+func (g *paramGraph) nodeLabel(node int) []string {
+
+	switch node {
+	case graphStart:
+		return []string{strings.Join(strings.Fields(g.command.Prefix), " ")}
+	case g.end:
+		return []string{"<cr>"}
+	}
+
+	p := &g.command.Params[node]
+	lines := []string{p.NameDesc.Name, p.syntax()}
+
+	if p.Optional {
+		lines[1] = "[" + lines[1] + "]"
+	}
+
+	if len(p.Condition) != 0 {
+		lines = append(lines, "when "+strings.Join(p.Condition, " and "))
+	}
+
+	if !g.reached[node] {
+		lines = append(lines, "unreachable")
+	}
+
+	return lines
+}
+
+func (g *paramGraph) name() string {
+	if g.command.Name == "" {
+		return g.nodeLabel(graphStart)[0]
+	}
+	return g.command.Name
+}
+
+//an edge to a param given on conditions
+func (g *paramGraph) conditional(e graphEdge) bool {
+	return e.to != g.end && len(g.command.Params[e.to].Condition) != 0
+}
+
+//id of a node in the graphs of every command, id is the one of the command
+func (g *paramGraph) nodeID(id string, node int) string {
+	switch node {
+	case graphStart:
+		return id + "_start"
+	case g.end:
+		return id + "_end"
+	}
+	return id + "_p" + strconv.Itoa(node)
+}
+
+func dotString(str string) string {
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"", "\n", "\\n").Replace(str) + "\""
+}
+
+func (g *paramGraph) writeDOT(w io.Writer, id string) {
+
+	node := func(n int) string {
+		return g.nodeID(id, n)
+	}
+
+	fmt.Fprintf(w, "\tsubgraph %s {\n", dotString("cluster_"+id))
+	fmt.Fprintf(w, "\t\tlabel=%s;\n", dotString(g.name()))
+
+	fmt.Fprintf(w, "\t\t%s [label=%s, shape=plaintext];\n", node(graphStart), dotString(g.nodeLabel(graphStart)[0]))
+
+	for j := range g.command.Params {
+
+		var attrs []string
+		if g.command.Params[j].Optional {
+			attrs = append(attrs, "style=rounded")
+		}
+		if !g.reached[j] {
+			attrs = append(attrs, "color=red")
+		}
+
+		fmt.Fprintf(w, "\t\t%s [label=%s%s];\n", node(j), dotString(strings.Join(g.nodeLabel(j), "\n")), strings.Join(append([]string{""}, attrs...), ", "))
+	}
+
+	if g.reached[g.end] {
+		fmt.Fprintf(w, "\t\t%s [label=%s, shape=plaintext];\n", node(g.end), dotString(g.nodeLabel(g.end)[0]))
+	}
+
+	for _, e := range g.edges {
+
+		var attrs []string
+		if label := g.edgeLabel(e); label != "" {
+			attrs = append(attrs, "label="+dotString(label))
+		}
+		if g.conditional(e) {
+			attrs = append(attrs, "style=dashed")
+		}
+
+		if len(attrs) == 0 {
+			fmt.Fprintf(w, "\t\t%s -> %s;\n", node(e.from), node(e.to))
+		} else {
+			fmt.Fprintf(w, "\t\t%s -> %s [%s];\n", node(e.from), node(e.to), strings.Join(attrs, ", "))
+		}
+	}
+
+	fmt.Fprintf(w, "\t}\n")
+}
+
+//the characters Mermaid takes as syntax are given by entity codes
+func mermaidString(str string) string {
+	return "\"" + strings.NewReplacer("\"", "#quot;", "<", "#lt;", ">", "#gt;", "|", "#124;", "\n", "<br/>").Replace(str) + "\""
+}
+
+func (g *paramGraph) writeMermaid(w io.Writer, id string) {
+
+	node := func(n int) string {
+		return g.nodeID(id, n)
+	}
+
+	label := func(n int) string {
+		return mermaidString(strings.Join(g.nodeLabel(n), "\n"))
+	}
+
+	fmt.Fprintf(w, "\tsubgraph %s [%s]\n", id, mermaidString(g.name()))
+
+	fmt.Fprintf(w, "\t\t%s([%s])\n", node(graphStart), label(graphStart))
+
+	for j := range g.command.Params {
+		if g.command.Params[j].Optional {
+			fmt.Fprintf(w, "\t\t%s(%s)\n", node(j), label(j))
+		} else {
+			fmt.Fprintf(w, "\t\t%s[%s]\n", node(j), label(j))
+		}
+		if !g.reached[j] {
+			fmt.Fprintf(w, "\t\tclass %s unreachable\n", node(j))
+		}
+	}
+
+	if g.reached[g.end] {
+		fmt.Fprintf(w, "\t\t%s([%s])\n", node(g.end), label(g.end))
+	}
+
+	for _, e := range g.edges {
+
+		arrow := "-->"
+		if g.conditional(e) {
+			arrow = "-.->"
+		}
+
+		if l := g.edgeLabel(e); l != "" {
+			arrow += "|" + mermaidString(l) + "|"
+		}
+
+		fmt.Fprintf(w, "\t\t%s %s %s\n", node(e.from), arrow, node(e.to))
+	}
+
+	fmt.Fprintf(w, "\tend\n")
+}
+
+//WriteGraph writes the graphs of how the params of the commands follow each other, for the commands
+//starting with the keywords given, every command for "". An edge to a param given on conditions is
+//dashed, an edge taken with some values of a selection only is labeled with them, and the params
+//which can never be given are marked unreachable. The built-in commands are left out.
+func (s *Completer) WriteGraph(w io.Writer, format GraphFormat, command string) error {
+
+	var found []*schemaCommand
+	for _, c := range s.current().lookup(CmdlineField(command).Strings()) {
+		if !c.builtin {
+			found = append(found, c)
+		}
+	}
+
+	if len(found) == 0 {
+		return unknownCommand(command)
+	}
+
+	out := bufio.NewWriter(w)
+
+	switch format {
+	case GraphDOT:
+		fmt.Fprintf(out, "digraph commands {\n\trankdir=LR;\n\tnode [shape=box];\n")
+		for i, c := range found {
+			c.paramGraph().writeDOT(out, "c"+strconv.Itoa(i))
+		}
+		fmt.Fprintf(out, "}\n")
+	case GraphMermaid:
+		fmt.Fprintf(out, "flowchart LR\n\tclassDef unreachable stroke:#f00,stroke-dasharray:4\n")
+		for i, c := range found {
+			c.paramGraph().writeMermaid(out, "c"+strconv.Itoa(i))
+		}
+	default:
+		return fmt.Errorf("unknown graph format %s", format)
+	}
+
+	return out.Flush()
+}
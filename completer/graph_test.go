@@ -0,0 +1,120 @@
+package completer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnreachableParams(t *testing.T) {
+
+	tests := []struct {
+		schema      string
+		command     string
+		unreachable []int
+	}{
+		{testSchema, "show interface", nil},
+		{testSchema, "set", nil},
+		{conditionalSchema, "port", []int{3}},
+		{`{"commands": [{"name": "a", "prefix": "a", "param": [
+		  {"name": "x", "type": "selection", "range": ["on", "off"]},
+		  {"name": "y", "type": "plain", "condition": ["{x} eq on", "{x} eq off"]},
+		  {"name": "z", "type": "plain", "condition": ["{y} eq 5"]},
+		  {"name": "w", "type": "plain", "condition": ["{x} in on off"]}
+		]}]}`, "a", []int{1, 2}},
+	}
+
+	for _, test := range tests {
+
+		c := newTestCompleter(t, test.schema)
+
+		found := c.current().lookup(strings.Fields(test.command))
+		if len(found) != 1 {
+			t.Fatalf("%s: %d commands", test.command, len(found))
+		}
+
+		if unreachable := found[0].unreachableParams(); !reflect.DeepEqual(unreachable, test.unreachable) {
+			t.Errorf("%s: unreachable %v, expected %v", test.command, unreachable, test.unreachable)
+		}
+	}
+}
+
+func TestWriteGraph(t *testing.T) {
+
+	tests := []struct {
+		schema  string
+		format  GraphFormat
+		command string
+		want    []string
+		absent  []string
+	}{
+		{conditionalSchema, GraphDOT, "port", []string{
+			"digraph commands {\n\trankdir=LR;\n\tnode [shape=box];\n\tsubgraph \"cluster_c0\" {\n\t\tlabel=\"port\";\n",
+			"\t\tc0_start [label=\"port\", shape=plaintext];\n",
+			"\t\tc0_p0 [label=\"mode\\naccess|trunk\"];\n",
+			"\t\tc0_p1 [label=\"vlan\\n<1-4094>\\nwhen {-} eq access\"];\n",
+			"\t\tc0_p3 [label=\"never\\n<never>\\nwhen {mode} eq bogus\\nunreachable\", color=red];\n",
+			"\t\tc0_end [label=\"<cr>\", shape=plaintext];\n",
+			"\t\tc0_start -> c0_p0;\n",
+			"\t\tc0_p0 -> c0_p1 [label=\"access\", style=dashed];\n",
+			"\t\tc0_p0 -> c0_p2 [label=\"trunk\", style=dashed];\n",
+			"\t\tc0_p1 -> c0_end;\n",
+			"\t\tc0_p2 -> c0_p2 [style=dashed];\n",
+			"\t}\n}\n",
+		}, []string{"c0_p0 -> c0_end", "-> c0_p3", "c0_p3 ->"}},
+		{conditionalSchema, GraphMermaid, "port", []string{
+			"flowchart LR\n\tclassDef unreachable stroke:#f00,stroke-dasharray:4\n\tsubgraph c0 [\"port\"]\n",
+			"\t\tc0_start([\"port\"])\n",
+			"\t\tc0_p0[\"mode<br/>access#124;trunk\"]\n",
+			"\t\tc0_p1[\"vlan<br/>#lt;1-4094#gt;<br/>when {-} eq access\"]\n",
+			"\t\tclass c0_p3 unreachable\n",
+			"\t\tc0_end([\"#lt;cr#gt;\"])\n",
+			"\t\tc0_start --> c0_p0\n",
+			"\t\tc0_p0 -.->|\"access\"| c0_p1\n",
+			"\t\tc0_p1 --> c0_end\n",
+			"\tend\n",
+		}, []string{"-.-> c0_p3", "--> c0_p3", "c0_p0 --> c0_end"}},
+		//every command starting with the keywords, each in a graph of its own
+		{testSchema, GraphDOT, "show", []string{
+			"subgraph \"cluster_c0\" {\n\t\tlabel=\"show interface\";\n",
+			"subgraph \"cluster_c1\" {\n\t\tlabel=\"show vlan\";\n",
+			"\t\tc0_p0 -> c0_p1 [style=dashed];\n",
+			"\t\tc0_p0 -> c0_end;\n",
+			"\t\tc0_p1 -> c0_end;\n",
+			"\t\tc1_start -> c1_p0;\n",
+		}, []string{"unreachable", "c0_p1 -> c0_p1"}},
+	}
+
+	for _, test := range tests {
+
+		c := newTestCompleter(t, test.schema)
+
+		var out strings.Builder
+		if err := c.WriteGraph(&out, test.format, test.command); err != nil {
+			t.Errorf("%s %s: %v", test.format, test.command, err)
+			continue
+		}
+
+		for _, want := range test.want {
+			if !strings.Contains(out.String(), want) {
+				t.Errorf("%s %s: no %q in\n%s", test.format, test.command, want, out.String())
+			}
+		}
+
+		for _, absent := range test.absent {
+			if strings.Contains(out.String(), absent) {
+				t.Errorf("%s %s: %q in\n%s", test.format, test.command, absent, out.String())
+			}
+		}
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	if err := c.WriteGraph(new(strings.Builder), GraphDOT, "nothing"); err == nil {
+		t.Errorf("graph of an unknown command")
+	}
+
+	if err := c.WriteGraph(new(strings.Builder), GraphFormat(9), "show"); err == nil {
+		t.Errorf("graph in an unknown format")
+	}
+}
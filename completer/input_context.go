@@ -119,12 +119,21 @@ func (c *cmdContext) lookup(name string) (value []string) {
 
 //获取命令上下文中的最后一个参数
 func (c *cmdContext) getLast() (name, value string, ok bool) {
+	return c.getBack(1)
+}
 
-	if c == nil {
+//获取命令上下文中倒数第n个参数
+func (c *cmdContext) getBack(n int) (name, value string, ok bool) {
+
+	if c == nil || c.nodes == nil {
 		return "", "", false
 	}
 
 	elem := c.nodes.Back()
+	for i := 1; i < n && elem != nil; i++ {
+		elem = elem.Prev()
+	}
+
 	if elem == nil {
 		ok = false
 		return
@@ -0,0 +1,245 @@
+package completer
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+type Severity int
+
+const (
+	SeverityError   Severity = iota //the schema can not be used
+	SeverityWarning                 //the schema works, but likely not as intended
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	default:
+		return fmt.Sprintf("Severity(%d)", int(s))
+	}
+}
+
+//Problem is something wrong found in a schema
+type Problem struct {
+	Severity Severity
+	Path     string //where it is, e.g. commands[2](show interface).param[1](detail)
+	Message  string
+}
+
+func (p Problem) String() string {
+	if p.Path == "" {
+		return fmt.Sprintf("%s: %s", p.Severity, p.Message)
+	}
+	return fmt.Sprintf("%s: %s: %s", p.Path, p.Severity, p.Message)
+}
+
+//SchemaError is returned by Setup when the schema has errors
+type SchemaError struct {
+	Problems []Problem
+}
+
+func (e *SchemaError) Error() string {
+
+	msgs := []string{"completer schema error"}
+
+	for _, p := range e.Problems {
+		if p.Severity == SeverityError {
+			msgs = append(msgs, p.String())
+		}
+	}
+
+	return strings.Join(msgs, "\n")
+}
+
+//the "//" comment lines of a schema
+var schemaComment = regexp.MustCompile(`(?m)^\s*//.*$`)
+
+//Lint checks a schema and reports every problem found, errors first. The schema can not include others.
+func Lint(source []byte, format SchemaFormat) (problems []Problem) {
+
+	loader := newSchemaLoader(nil)
+	loader.loadBytes(source, format, "")
+
+	return loader.finish(true)
+}
+
+//LintFile checks a schema file with the files it includes, or a directory of schema files
+func LintFile(path string) (problems []Problem) {
+
+	loader := newSchemaLoader(diskFiles{})
+	loader.loadPath(path)
+
+	return loader.finish(true)
+}
+
+func hasErrors(problems []Problem) bool {
+	for _, p := range problems {
+		if p.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+//path in the file the schema is loaded from
+func sourcePath(source string, path string) string {
+	if source == "" {
+		return path
+	}
+	return source + ": " + path
+}
+
+func commandPath(c *schemaCommand) string {
+	return sourcePath(c.source, fmt.Sprintf("commands[%d](%s)", c.index, c.Name))
+}
+
+func paramPath(c *schemaCommand, j int, p *schemaParam) string {
+
+	name := p.NameDesc.Name
+	if name == "" {
+		name = p.Ref
+	}
+
+	return fmt.Sprintf("%s.param[%d](%s)", commandPath(c), j, name)
+}
+
+//the problems of loading and preparing the schema, errors first. The checks only linting needs are
+//done if lint is set.
+func (l *schemaLoader) finish(lint bool) (problems []Problem) {
+
+	problems = append(l.problems, l.schema.prepare(lint)...)
+
+	sort.SliceStable(problems, func(i, j int) bool {
+		return problems[i].Severity < problems[j].Severity
+	})
+
+	return
+}
+
+//the shared param a param refers to, with the name, conditions and flags of the param
+func (p *schemaParam) resolve(shared schemaParam) (resolved schemaParam) {
+
+	resolved = shared
+	resolved.Ref = p.Ref
+	resolved.Optional = shared.Optional || p.Optional
+	resolved.Unique = shared.Unique || p.Unique
+
+	if p.NameDesc.Name != "" {
+		resolved.NameDesc = p.NameDesc
+	}
+
+	if len(p.Condition) != 0 {
+		resolved.Condition = p.Condition
+	}
+
+	return
+}
+
+//prepare the commands of the schema to be used and check them, the params referring to the shared ones are resolved.
+//The params are searched for unreachable ones if lint is set, that walks every way of taking each command.
+func (t *schemaTop) prepare(lint bool) (problems []Problem) {
+
+	report := func(severity Severity, path string, format string, args ...interface{}) {
+		problems = append(problems, Problem{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	prefixes := make(map[string]*schemaCommand)
+
+	for i := range t.Commands {
+
+		c := &t.Commands[i]
+		c.staticParams, c.dynamParams = nil, nil
+
+		prefix := strings.Join(strings.Fields(c.Prefix), " ")
+
+		if prefix == "" {
+			report(SeverityError, commandPath(c), "empty prefix")
+		} else if first, exist := prefixes[prefix]; exist {
+			report(SeverityError, commandPath(c), "prefix \"%s\" is already used by %s", prefix, commandPath(first))
+		} else {
+			prefixes[prefix] = c
+		}
+
+		start := len(problems)
+		names := make(map[string]bool)
+
+		for j := range c.Params {
+
+			p := &c.Params[j]
+
+			if p.Ref != "" {
+				shared, exist := t.Params[p.Ref]
+				if exist && shared.Ref == "" {
+					*p = p.resolve(shared)
+				}
+			}
+
+			path := paramPath(c, j, p)
+
+			if len(p.Condition) == 0 {
+				c.staticParams = append(c.staticParams, p)
+			} else {
+				c.dynamParams = append(c.dynamParams, p)
+			}
+
+			if shared, exist := t.Params[p.Ref]; p.Ref != "" && !exist {
+				report(SeverityError, path, "unknown shared param \"%s\"", p.Ref)
+				continue
+			} else if shared.Ref != "" {
+				report(SeverityError, path, "shared param \"%s\" refers to another one", p.Ref)
+				continue
+			}
+
+			if p.NameDesc.Name == "" {
+				report(SeverityError, path, "empty name")
+			} else if names[p.NameDesc.Name] {
+				report(SeverityWarning, path, "name is used by another param of the command")
+			}
+			names[p.NameDesc.Name] = true
+
+			sels, _, err := rangeDecodeSelection(p.Range)
+
+			switch {
+			case p.Type == paramTypeInteger:
+				if _, _, _, err := rangeDecodeInteger(p.Range); err != nil {
+					report(SeverityError, path, "%s", err.Error())
+				}
+			case err != nil:
+				report(SeverityError, path, "range must be a string or a list of strings")
+			case p.Type == paramTypeSelection && len(sels) == 0:
+				report(SeverityError, path, "selection without range")
+			case p.Type == paramTypePlain && len(sels) != 0:
+				report(SeverityWarning, path, "range of a plain param is ignored")
+			}
+
+			if err := p.compileConditions(); err != nil {
+				report(SeverityError, path, "%s", err.Error())
+			}
+		}
+
+		for j := range c.Params {
+			p := &c.Params[j]
+			for _, cond := range p.conditions {
+				for _, name := range cond.names {
+					if !names[name] {
+						report(SeverityError, paramPath(c, j, p), "condition refers to unknown param \"%s\"", name)
+					}
+				}
+			}
+		}
+
+		if lint && !hasErrors(problems[start:]) {
+			for _, j := range c.unreachableParams() {
+				report(SeverityWarning, paramPath(c, j, &c.Params[j]), "unreachable, its conditions are never met")
+			}
+		}
+	}
+
+	return
+}
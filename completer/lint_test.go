@@ -0,0 +1,99 @@
+package completer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		schema   string
+		problems []string
+	}{
+		{"clean", testSchema, nil},
+		{"unreachable", conditionalSchema, []string{
+			"commands[0](port).param[3](never): warning: unreachable, its conditions are never met",
+		}},
+		{"prefixes", `{"commands": [{"name": "a", "prefix": " "}, {"name": "b", "prefix": "show  x"}, {"name": "c", "prefix": "show x"}]}`, []string{
+			"commands[0](a): error: empty prefix",
+			`commands[2](c): error: prefix "show x" is already used by commands[1](b)`,
+		}},
+		//errors first, then warnings
+		{"params", `{"commands": [{"name": "a", "prefix": "a", "param": [
+		  {"name": "", "type": "plain"},
+		  {"name": "x", "type": "plain", "range": ["q"]},
+		  {"name": "x", "type": "selection"},
+		  {"name": "n", "type": "integer", "range": "9-1"},
+		  {"name": "s", "type": "selection", "range": 5},
+		  {"name": "c", "type": "plain", "condition": ["{nope} eq 1"]},
+		  {"name": "d", "type": "plain", "condition": ["{x} =5"]},
+		  {"name": "e", "type": "plain", "condition": ["{x} eq"]},
+		  {"name": "r", "ref": "missing"}
+		]}]}`, []string{
+			"commands[0](a).param[0](): error: empty name",
+			"commands[0](a).param[2](x): error: selection without range",
+			`commands[0](a).param[3](n): error: invalid integer range "9-1"`,
+			"commands[0](a).param[4](s): error: range must be a string or a list of strings",
+			`commands[0](a).param[6](d): error: condition "{x} =5": unknown operator '=', a value starting with it is to be quoted`,
+			`commands[0](a).param[7](e): error: condition "{x} eq": operand expected at the end`,
+			`commands[0](a).param[8](r): error: unknown shared param "missing"`,
+			`commands[0](a).param[5](c): error: condition refers to unknown param "nope"`,
+			"commands[0](a).param[1](x): warning: range of a plain param is ignored",
+			"commands[0](a).param[2](x): warning: name is used by another param of the command",
+		}},
+		{"shared", `{"params": {"p": {"name": "p", "ref": "q"}, "q": {"name": "q", "type": "plain"}},
+		  "commands": [{"name": "a", "prefix": "a", "param": [{"ref": "p"}, {"ref": "q"}]}]}`, []string{
+			`commands[0](a).param[0](p): error: shared param "p" refers to another one`,
+		}},
+		{"syntax", `{"commands": [{"name": "a", "prefix": "a"}`, []string{
+			"error: unexpected end of JSON input",
+		}},
+	}
+
+	for _, test := range tests {
+
+		var problems []string
+		for _, p := range Lint([]byte(test.schema), FormatJSON) {
+			problems = append(problems, p.String())
+		}
+
+		if !reflect.DeepEqual(problems, test.problems) {
+			t.Errorf("%s: %q, expected %q", test.name, problems, test.problems)
+		}
+	}
+}
+
+//a schema with errors is not set up, one with warnings only is
+func TestSetupLinted(t *testing.T) {
+
+	err := new(Completer).SetupBytes([]byte(`{"commands": [{"name": "a", "prefix": "a", "param": [
+	  {"name": "x", "type": "plain", "range": ["q"]},
+	  {"name": "s", "type": "selection"}
+	]}]}`), FormatJSON)
+
+	schemaErr, ok := err.(*SchemaError)
+	if !ok {
+		t.Fatalf("%v, expected a *SchemaError", err)
+	}
+
+	if len(schemaErr.Problems) != 2 || strings.Contains(schemaErr.Error(), "warning") ||
+		!strings.Contains(schemaErr.Error(), "commands[0](a).param[1](s): error: selection without range") {
+		t.Errorf("%q", schemaErr.Error())
+	}
+
+	newTestCompleter(t, conditionalSchema)
+}
+
+//the params are searched for unreachable ones when linting only, not each time a schema is set up
+func TestPrepareNotLinted(t *testing.T) {
+
+	loader := newSchemaLoader(nil)
+	loader.loadBytes([]byte(conditionalSchema), FormatJSON, "")
+
+	if problems := loader.finish(false); len(problems) != 0 {
+		t.Errorf("%v, expected no problem", problems)
+	}
+}
@@ -0,0 +1,231 @@
+package completer
+
+import (
+	"fmt"
+	"io/fs"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"sort"
+)
+
+//schemaFiles is where the schema files are read from
+type schemaFiles interface {
+	stat(name string) (fs.FileInfo, error)
+	readDir(name string) ([]fs.FileInfo, error)
+	readFile(name string) ([]byte, error)
+	glob(pattern string) ([]string, error)
+	include(from string, name string) string //the path of a file included by another
+	key(name string) string                  //the same for every path of a file
+}
+
+//the files on disk
+type diskFiles struct{}
+
+func (diskFiles) stat(name string) (fs.FileInfo, error)      { return os.Stat(name) }
+func (diskFiles) readDir(name string) ([]fs.FileInfo, error) { return ioutil.ReadDir(name) }
+func (diskFiles) readFile(name string) ([]byte, error)       { return ioutil.ReadFile(name) }
+func (diskFiles) glob(pattern string) ([]string, error)      { return filepath.Glob(pattern) }
+
+func (diskFiles) include(from string, name string) string {
+	if filepath.IsAbs(name) {
+		return name
+	}
+	return filepath.Join(filepath.Dir(from), name)
+}
+
+func (diskFiles) key(name string) string {
+	if abs, err := filepath.Abs(name); err == nil {
+		return abs
+	}
+	return name
+}
+
+//the files of a fs.FS, e.g. an embed.FS
+type fsFiles struct {
+	fsys fs.FS
+}
+
+func (f fsFiles) stat(name string) (fs.FileInfo, error) { return fs.Stat(f.fsys, name) }
+func (f fsFiles) readFile(name string) ([]byte, error)  { return fs.ReadFile(f.fsys, name) }
+func (f fsFiles) glob(pattern string) ([]string, error) { return fs.Glob(f.fsys, pattern) }
+func (f fsFiles) key(name string) string                { return path.Clean(name) }
+
+func (f fsFiles) readDir(name string) (infos []fs.FileInfo, err error) {
+
+	entries, err := fs.ReadDir(f.fsys, name)
+
+	for _, e := range entries {
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		infos = append(infos, info)
+	}
+
+	return
+}
+
+//the paths of a fs.FS are relative to its root, an include starting with "/" too
+func (fsFiles) include(from string, name string) string {
+	if path.IsAbs(name) {
+		return path.Clean(name[1:])
+	}
+	return path.Join(path.Dir(from), name)
+}
+
+//schemaLoader merges schema files into one schema. A file may include others, by paths relative
+//to it, glob patterns or directories. A file is loaded once however many times it is included.
+type schemaLoader struct {
+	schema       schemaTop
+	files        schemaFiles
+	loaded       map[string]bool
+	paramSources map[string]string //where the shared params are defined
+	watched      []string          //the files and directories loaded or tried
+	problems     []Problem
+}
+
+func newSchemaLoader(files schemaFiles) *schemaLoader {
+	return &schemaLoader{
+		schema:       schemaTop{Version: SchemaVersion, Params: make(map[string]schemaParam)},
+		files:        files,
+		loaded:       make(map[string]bool),
+		paramSources: make(map[string]string),
+	}
+}
+
+func (l *schemaLoader) report(severity Severity, path string, format string, args ...interface{}) {
+	l.problems = append(l.problems, Problem{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+//is the file a schema, by its extension
+func isSchemaFile(path string) bool {
+	switch filepath.Ext(path) {
+	case ".json", ".yaml", ".yml", ".toml":
+		return true
+	}
+	return false
+}
+
+//load a schema file, or the schema files of a directory in the order of their names
+func (l *schemaLoader) loadPath(path string) {
+
+	l.watched = append(l.watched, path)
+
+	info, err := l.files.stat(path)
+	if err != nil {
+		l.report(SeverityError, path, "%s", err.Error())
+		return
+	}
+
+	if !info.IsDir() {
+		l.loadFile(path)
+		return
+	}
+
+	entries, err := l.files.readDir(path)
+	if err != nil {
+		l.report(SeverityError, path, "%s", err.Error())
+		return
+	}
+
+	for _, e := range entries {
+		if !e.IsDir() && isSchemaFile(e.Name()) {
+			l.loadFile(l.files.include(path+"/", e.Name()))
+		}
+	}
+}
+
+func (l *schemaLoader) loadFile(path string) {
+
+	key := l.files.key(path)
+	if l.loaded[key] {
+		return
+	}
+	l.loaded[key] = true
+
+	l.watched = append(l.watched, path)
+
+	source, err := l.files.readFile(path)
+	if err != nil {
+		l.report(SeverityError, path, "%s", err.Error())
+		return
+	}
+
+	l.loadBytes(source, FormatOf(path), path)
+}
+
+//load a schema, its includes are read from the files of the loader. source is where it comes from, "" if unknown.
+func (l *schemaLoader) loadBytes(data []byte, format SchemaFormat, source string) {
+
+	var fragment schemaTop
+
+	if err := decodeSchema(data, format, &fragment); err != nil {
+		l.report(SeverityError, source, "%s", err.Error())
+		return
+	}
+
+	l.merge(&fragment, source)
+
+	for _, include := range fragment.Include {
+
+		if l.files == nil {
+			l.report(SeverityError, sourcePath(source, "include"), "no files to include from")
+			break
+		}
+
+		include = l.files.include(source, include)
+
+		//for the files added to the directory later
+		l.watched = append(l.watched, l.files.include(include, "."))
+
+		matches, err := l.files.glob(include)
+		if err != nil || len(matches) == 0 {
+			l.report(SeverityError, sourcePath(source, "include"), "\"%s\" matches no file", include)
+			continue
+		}
+
+		sort.Strings(matches)
+		for _, m := range matches {
+			l.loadPath(m)
+		}
+	}
+}
+
+//add the commands and the shared params of a file to the schema
+func (l *schemaLoader) merge(fragment *schemaTop, source string) {
+
+	if fragment.Version > SchemaVersion || fragment.Version < 0 {
+		l.report(SeverityError, source, "version %d is not supported, the latest is %d", fragment.Version, SchemaVersion)
+		return
+	}
+
+	for i := range fragment.Commands {
+		c := fragment.Commands[i]
+		c.source, c.index = source, i
+		l.schema.Commands = append(l.schema.Commands, c)
+	}
+
+	names := make([]string, 0, len(fragment.Params))
+	for name := range fragment.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+
+		p := fragment.Params[name]
+
+		if defined, exist := l.schema.Params[name]; exist {
+			if !reflect.DeepEqual(defined, p) {
+				l.report(SeverityError, sourcePath(source, "params."+name), "already defined differently in %s", l.paramSources[name])
+			}
+			continue
+		}
+
+		l.schema.Params[name] = p
+		l.paramSources[name] = source
+	}
+}
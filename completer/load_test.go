@@ -0,0 +1,209 @@
+package completer
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+//write the schema files to a temporary directory, by their paths in it
+func writeSchemaFiles(t *testing.T, files map[string]string) (dir string) {
+	t.Helper()
+
+	dir = t.TempDir()
+
+	for name, content := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return
+}
+
+func TestSetupIncludes(t *testing.T) {
+
+	dir := writeSchemaFiles(t, map[string]string{
+		"main.json": `{"include": ["cmds", "shared.json"],
+		  "commands": [{"name": "show", "prefix": "show", "param": [{"ref": "ifname"}]}]}`,
+		//shared.json is included twice, it is loaded once
+		"cmds/clear.json": `{"include": ["../shared.json"],
+		  "commands": [{"name": "clear", "prefix": "clear", "param": [{"ref": "ifname", "optional": true}]}]}`,
+		"cmds/reset.json": `{"commands": [{"name": "reset", "prefix": "reset"}]}`,
+		"cmds/notes.txt":  `not a schema`,
+		"shared.json": `{"params": {"ifname": {"name": "ifname: interface name", "type": "plain"}},
+		  "commands": [{"name": "ping", "prefix": "ping"}]}`,
+		"more/exit.json": `{"commands": [{"name": "exit", "prefix": "exit"}]}`,
+	})
+
+	c := new(Completer)
+	if err := c.Setup(filepath.Join(dir, "main.json"), filepath.Join(dir, "more")); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"show eth0", "clear", "clear eth0", "reset", "ping", "exit"} {
+		if err := c.Validate(input); err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+	}
+
+	//the shared param with the flags of the param referring to it
+	if err := c.Validate("show"); err == nil {
+		t.Errorf("show: validated without the interface")
+	}
+
+	if usages, _ := c.Usage("clear"); !reflect.DeepEqual(usages, []string{"clear [<ifname>]"}) {
+		t.Errorf("clear: %q", usages)
+	}
+}
+
+func TestLintFileIncludes(t *testing.T) {
+
+	tests := []struct {
+		name     string
+		files    map[string]string
+		path     string
+		problems []string
+	}{
+		{"conflicts", map[string]string{
+			"a.json": `{"include": ["b.json", "none/*.json"], "params": {"p": {"name": "p", "type": "plain"}},
+			  "commands": [{"name": "show", "prefix": "show"}]}`,
+			"b.json": `{"params": {"p": {"name": "p", "type": "selection", "range": ["x"]}},
+			  "commands": [{"name": "show2", "prefix": "show", "param": [{"ref": "p"}]}]}`,
+		}, "a.json", []string{
+			"b.json: params.p: error: already defined differently in a.json",
+			`a.json: include: error: "none/*.json" matches no file`,
+			`b.json: commands[0](show2): error: prefix "show" is already used by a.json: commands[0](show)`,
+		}},
+		//the same shared param may be defined in more than one file
+		{"same param", map[string]string{
+			"a.json": `{"include": ["b.json"], "params": {"p": {"name": "p", "type": "plain"}},
+			  "commands": [{"name": "a", "prefix": "a", "param": [{"ref": "p"}]}]}`,
+			"b.json": `{"params": {"p": {"name": "p", "type": "plain"}},
+			  "commands": [{"name": "b", "prefix": "b", "param": [{"ref": "p"}]}]}`,
+		}, "a.json", nil},
+		{"cycle", map[string]string{
+			"a.json": `{"include": ["b.json"], "commands": [{"name": "a", "prefix": "a"}]}`,
+			"b.json": `{"include": ["a.json"], "commands": [{"name": "b", "prefix": "b"}]}`,
+		}, "a.json", nil},
+		{"unknown ref", map[string]string{
+			"a.json": `{"commands": [{"name": "a", "prefix": "a", "param": [{"ref": "p"}]}]}`,
+		}, "a.json", []string{
+			`a.json: commands[0](a).param[0](p): error: unknown shared param "p"`,
+		}},
+	}
+
+	for _, test := range tests {
+
+		dir := writeSchemaFiles(t, test.files)
+
+		var problems []string
+		for _, p := range LintFile(filepath.Join(dir, test.path)) {
+			problems = append(problems, strings.ReplaceAll(p.String(), dir+string(filepath.Separator), ""))
+		}
+
+		if !reflect.DeepEqual(problems, test.problems) {
+			t.Errorf("%s: %q, expected %q", test.name, problems, test.problems)
+		}
+	}
+
+	if problems := LintFile(filepath.Join(t.TempDir(), "missing.json")); !hasErrors(problems) {
+		t.Errorf("a missing file linted with %q", problems)
+	}
+}
+
+//a schema given by bytes has no files to include from
+func TestSetupBytesInclude(t *testing.T) {
+
+	err := new(Completer).SetupBytes([]byte(`{"include": ["a.json"]}`), FormatJSON)
+
+	if err == nil || !strings.Contains(err.Error(), "include: error: no files to include from") {
+		t.Errorf("%v", err)
+	}
+}
+
+func TestSetupFS(t *testing.T) {
+
+	fsys := fstest.MapFS{
+		"schema/main.yaml": {Data: []byte("include: [cmds, /common/shared.toml]\ncommands:\n  - {name: show, prefix: show, param: [{ref: ifname}]}\n")},
+		"schema/cmds/clear.json": {Data: []byte(`{"include": ["../../common/shared.toml"],
+		  "commands": [{"name": "clear", "prefix": "clear", "param": [{"ref": "ifname"}]}]}`)},
+		"common/shared.toml": {Data: []byte("[params.ifname]\nname = \"ifname\"\ntype = \"plain\"\n\n[[commands]]\nname = \"ping\"\nprefix = \"ping\"\n")},
+	}
+
+	c := new(Completer)
+	if err := c.SetupFS(fsys, "schema/main.yaml"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, input := range []string{"show eth0", "clear eth0", "ping"} {
+		if err := c.Validate(input); err != nil {
+			t.Errorf("%q: %v", input, err)
+		}
+	}
+
+	if err := new(Completer).SetupFS(fsys, "schema/missing.json"); err == nil {
+		t.Errorf("a missing file set up")
+	}
+}
+
+type failingReader struct{}
+
+func (failingReader) Read(p []byte) (int, error) { return 0, errors.New("read failed") }
+
+func TestSetupReader(t *testing.T) {
+
+	c := new(Completer)
+	if err := c.SetupReader(strings.NewReader(testSchema), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate("show vlan 10"); err != nil {
+		t.Errorf("show vlan 10: %v", err)
+	}
+
+	if err := c.SetupReader(failingReader{}, FormatJSON); err == nil || err.Error() != "read failed" {
+		t.Errorf("%v", err)
+	}
+}
+
+//the schema in use is kept if the one reloaded has errors
+func TestReloadError(t *testing.T) {
+
+	dir := writeSchemaFiles(t, map[string]string{"a.json": `{"commands": [{"name": "a", "prefix": "a"}]}`})
+	path := filepath.Join(dir, "a.json")
+
+	c := new(Completer)
+	if err := c.Setup(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := c.Reload().(*SchemaError); !ok {
+		t.Errorf("reloaded a broken schema")
+	}
+
+	if err := c.Validate("a"); err != nil {
+		t.Errorf("a: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [{"name": "b", "prefix": "b"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reload(); err != nil || c.Validate("b") != nil || c.Validate("a") == nil {
+		t.Errorf("reloaded with %v", err)
+	}
+}
@@ -0,0 +1,282 @@
+package completer
+
+import (
+	"fmt"
+	"strings"
+)
+
+//paramNext is a param which may be given next in a command line
+type paramNext struct {
+	param          *schemaParam
+	staticParamPos int
+}
+
+//nextParams lists the params which may follow param, which has been given at staticParamPos.
+//param is nil for the first param after the command prefix.
+//The static params go in order, an optional one may be skipped. The dynamic ones come whenever
+//their conditions are met. A unique param is never given twice.
+func (c *schemaCommand) nextParams(param *schemaParam, staticParamPos int, context *cmdContext) (nexts []paramNext) {
+
+	given := func(p *schemaParam) bool {
+		return p.Unique && context.count(p.NameDesc.Name) > 0
+	}
+
+	nextStatic := -1
+
+	if param == nil {
+		nextStatic = 0
+	} else {
+		for _, p := range c.staticParams {
+			if p == param {
+				nextStatic = staticParamPos + 1
+			}
+		}
+	}
+
+	if nextStatic >= 0 {
+		for i := nextStatic; i < len(c.staticParams); i++ {
+			if !given(c.staticParams[i]) {
+				nexts = append(nexts, paramNext{c.staticParams[i], i})
+			}
+			if !c.staticParams[i].Optional {
+				break
+			}
+		}
+	}
+
+	for _, p := range c.dynamParams {
+		if !given(p) && p.conditionCheck(context) {
+			nexts = append(nexts, paramNext{p, staticParamPos})
+		}
+	}
+
+	return
+}
+
+//matchValue returns the values of the param the input can stand for.
+//A selection is matched case-insensitively, by its full name or by an abbreviation of it,
+//a plain param takes the input as it is, an integer one if it is a number of its range.
+func (param *schemaParam) matchValue(input string) (values []string, exact bool) {
+
+	switch param.Type {
+	case paramTypePlain:
+		return []string{input}, true
+	case paramTypeInteger:
+		if param.checkValue(input) {
+			return []string{input}, true
+		}
+	case paramTypeSelection:
+		sels, _, _ := rangeDecodeSelection(param.Range)
+		for _, s := range stringsUniq(sels) {
+			if s == "" {
+				continue
+			}
+			if strings.EqualFold(s, input) {
+				return []string{s}, true
+			}
+			if strings.HasPrefix(strings.ToLower(s), strings.ToLower(input)) {
+				values = append(values, s)
+			}
+		}
+	}
+
+	return
+}
+
+//how a token has been taken, the lower the better
+type matchRank int
+
+const (
+	rankKeyword            matchRank = iota //the full name of a prefix segment or selection
+	rankAbbreviated                         //an abbreviation of a prefix segment or selection
+	rankBuiltinAbbreviated                  //an abbreviation of a keyword of a built-in command, the schema commands come first
+	rankPlain                               //the value of a plain param
+)
+
+//cmdMatch is a way a command line is understood by a command of the schema
+type cmdMatch struct {
+	command        *schemaCommand
+	param          *schemaParam //the param the last token is taken as, nil while in the prefix
+	staticParamPos int
+	context        *cmdContext
+	words          []string       //the full form of each token
+	params         []*schemaParam //the param each token is taken as, nil for the keywords of the prefix
+	ranks          []matchRank
+}
+
+func (m *cmdMatch) extend(param paramNext, word string, rank matchRank) (extended *cmdMatch) {
+
+	extended = &cmdMatch{
+		command:        m.command,
+		param:          param.param,
+		staticParamPos: param.staticParamPos,
+		context:        m.context.clone(),
+		words:          append(append([]string{}, m.words...), word),
+		params:         append(append([]*schemaParam{}, m.params...), param.param),
+		ranks:          append(append([]matchRank{}, m.ranks...), rank),
+	}
+
+	extended.context.append(param.param.NameDesc.Name, word)
+	return
+}
+
+//match the tokens of a command line against the command, returns every way all of the tokens can be taken.
+//If there is none, progress is the index of the first token no way could take.
+func (c *schemaCommand) match(tokens []string) (matches []*cmdMatch, progress int) {
+
+	prefixSegs := strings.Fields(c.Prefix)
+
+	if len(prefixSegs) == 0 {
+		return
+	}
+
+	root := &cmdMatch{command: c, context: new(cmdContext)}
+	root.context.init()
+
+	for i := 0; i < len(prefixSegs) && i < len(tokens); i++ {
+
+		switch {
+		case strings.EqualFold(prefixSegs[i], tokens[i]):
+			root.ranks = append(root.ranks, rankKeyword)
+		case !strings.HasPrefix(strings.ToLower(prefixSegs[i]), strings.ToLower(tokens[i])):
+			return nil, i
+		case c.builtin:
+			root.ranks = append(root.ranks, rankBuiltinAbbreviated)
+		default:
+			root.ranks = append(root.ranks, rankAbbreviated)
+		}
+
+		root.words = append(root.words, prefixSegs[i])
+		root.params = append(root.params, nil)
+	}
+
+	matches = []*cmdMatch{root}
+
+	for i := len(prefixSegs); i < len(tokens); i++ {
+
+		var nextMatches []*cmdMatch
+
+		for _, m := range matches {
+			for _, next := range c.nextParams(m.param, m.staticParamPos, m.context) {
+
+				values, exact := next.param.matchValue(tokens[i])
+
+				rank := rankAbbreviated
+				if next.param.Type != paramTypeSelection {
+					rank = rankPlain
+				} else if exact {
+					rank = rankKeyword
+				}
+
+				for _, v := range values {
+					nextMatches = append(nextMatches, m.extend(next, v, rank))
+				}
+			}
+		}
+
+		if len(nextMatches) == 0 {
+			return nil, i
+		}
+
+		matches = nextMatches
+	}
+
+	return matches, len(tokens)
+}
+
+//pick the best ways of taking the tokens, token by token: keywords over abbreviations over plain values
+func bestMatches(matches []*cmdMatch, tokenCnt int) []*cmdMatch {
+
+	for i := 0; i < tokenCnt && len(matches) > 1; i++ {
+
+		best := rankPlain
+		for _, m := range matches {
+			if m.ranks[i] < best {
+				best = m.ranks[i]
+			}
+		}
+
+		var kept []*cmdMatch
+		for _, m := range matches {
+			if m.ranks[i] == best {
+				kept = append(kept, m)
+			}
+		}
+		matches = kept
+	}
+
+	return matches
+}
+
+//the first token the ways of taking the tokens do not agree on, and the keywords it stands for then.
+//candidates is nil if they all agree.
+func ambiguousToken(matches []*cmdMatch, tokenCnt int) (index int, candidates []string) {
+
+	for i := 0; i < tokenCnt; i++ {
+
+		words := []string{}
+		for _, m := range matches {
+			words = append(words, m.words[i])
+		}
+
+		if words = stringsUniq(words); len(words) > 1 {
+			return i, words
+		}
+	}
+
+	return 0, nil
+}
+
+//AmbiguousError tells that an abbreviated token of a command line stands for more than one keyword
+type AmbiguousError struct {
+	Token      string
+	Index      int //index of the token in the command line
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	return fmt.Sprintf("ambiguous command: \"%s\" could be %s", e.Token, strings.Join(e.Candidates, ", "))
+}
+
+//Expand replaces the abbreviated keywords of a command line with their full form, e.g. "sh int br" is
+//expanded to "show interface brief". The rest of the line is kept as it is, spaces and quotes included.
+//An *AmbiguousError is returned if an abbreviation is not unique. A line the schema does not understand
+//is returned unchanged.
+func (s *Completer) Expand(input string) (expanded string, err error) {
+
+	fields := CmdlineField(input)
+	segs := fields.Strings()
+
+	if len(segs) == 0 {
+		return input, nil
+	}
+
+	var matches []*cmdMatch
+	schema := s.current()
+
+	for i := range schema.Commands {
+		found, _ := schema.Commands[i].match(segs)
+		matches = append(matches, found...)
+	}
+
+	if len(matches) == 0 {
+		return input, nil
+	}
+
+	matches = bestMatches(matches, len(segs))
+
+	if i, candidates := ambiguousToken(matches, len(segs)); len(candidates) != 0 {
+		return input, &AmbiguousError{Token: segs[i], Index: i, Candidates: candidates}
+	}
+
+	//splice the full forms into the line, from the end for the offsets to hold
+	expanded = input
+	for i := len(segs) - 1; i >= 0; i-- {
+		if w := matches[0].words[i]; w != segs[i] {
+			seg := fields.Segs()[i]
+			expanded = expanded[:seg.Offset()] + Quote(w) + expanded[seg.End():]
+		}
+	}
+
+	return expanded, nil
+}
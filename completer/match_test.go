@@ -0,0 +1,138 @@
+package completer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+//optional static params between required ones, a unique dynamic param and one which may be given again
+const optionalSchema = `{"commands": [
+ {"name": "route", "prefix": "route",
+  "param": [
+   {"name": "dest", "type": "plain"},
+   {"name": "metric", "type": "integer", "range": "1-100", "optional": true},
+   {"name": "table", "type": "selection", "range": ["main", "local"], "optional": true},
+   {"name": "dev", "type": "plain"},
+   {"name": "verbose", "type": "selection", "range": ["verbose"], "condition": ["*"], "optional": true, "uniq": true},
+   {"name": "log", "type": "selection", "range": ["log"], "condition": ["*"], "optional": true}
+  ]}
+]}`
+
+func TestOptionalParams(t *testing.T) {
+
+	c := newTestCompleter(t, optionalSchema)
+
+	tests := []struct {
+		input string
+		kind  SyntaxErrorKind //of the error, if not ok
+		ok    bool
+		index int
+	}{
+		{input: "route x eth0", ok: true},
+		{input: "route x 5 eth0", ok: true},
+		{input: "route x main eth0", ok: true},
+		{input: "route x 5 main eth0", ok: true},
+		{input: "route x eth0 log log", ok: true},
+		{input: "route x eth0 verbose log", ok: true},
+		{input: "route x eth0 log verbose log", ok: true},
+		{input: "route x", kind: SyntaxIncomplete, index: 2},
+		{input: "route x 5", ok: true}, //5 is the dev
+		{input: "route x main", kind: SyntaxIncomplete, index: 3},
+		//the static params go in order
+		{input: "route x main 5 eth0", kind: SyntaxInvalidInput, index: 4},
+		//the unique param is not given twice
+		{input: "route x eth0 verbose verbose", kind: SyntaxInvalidInput, index: 4},
+		{input: "route x eth0 verbose log verbose", kind: SyntaxInvalidInput, index: 5},
+	}
+
+	for _, test := range tests {
+
+		err := c.Validate(test.input)
+
+		if test.ok {
+			if err != nil || !c.Executable(test.input) {
+				t.Errorf("%q: %v", test.input, err)
+			}
+			continue
+		}
+
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: %v, expected a *SyntaxError", test.input, err)
+			continue
+		}
+
+		if syntaxErr.Kind != test.kind || syntaxErr.Index != test.index {
+			t.Errorf("%q: %s at %d, expected %s at %d", test.input, syntaxErr.Kind, syntaxErr.Index, test.kind, test.index)
+		}
+
+		if c.Executable(test.input) {
+			t.Errorf("%q: executable", test.input)
+		}
+	}
+}
+
+func TestOptionalParamsCompletion(t *testing.T) {
+
+	c := newTestCompleter(t, optionalSchema)
+
+	tests := []struct {
+		input  string
+		values []string
+		cr     bool //is <cr> in the helps
+	}{
+		{"route x ", []string{"local", "log", "main", "verbose"}, false},
+		{"route x main ", []string{"log", "verbose"}, false},
+		{"route x eth0 ", []string{"log", "verbose"}, true},
+		//given once, the unique param is not completed again, the other one is
+		{"route x eth0 verbose ", []string{"log"}, true},
+		{"route x eth0 log ", []string{"log", "verbose"}, true},
+	}
+
+	for _, test := range tests {
+
+		candidates, _ := c.Complete(test.input)
+
+		var values []string
+		for _, candidate := range candidates {
+			values = append(values, candidate.Value)
+		}
+
+		if !reflect.DeepEqual(values, test.values) {
+			t.Errorf("%q: completed with %q, expected %q", test.input, values, test.values)
+		}
+
+		helps := c.GetHelps(test.input)
+		if cr := strings.Contains(helps, "<cr>"); cr != test.cr {
+			t.Errorf("%q: <cr> %v in the helps:\n%s", test.input, cr, helps)
+		}
+	}
+}
+
+func TestCompletionSuffix(t *testing.T) {
+
+	tests := []struct {
+		rest   string
+		quote  byte
+		fresh  bool
+		suffix string
+	}{
+		//out of quotes the spaces, quotes and backslashes are escaped
+		{"my port", 0, false, `my\ port `},
+		{`a"b\c`, 0, false, `a\"b\\c `},
+		//a field not started yet is quoted as a whole if it needs to be
+		{"my port", 0, true, `"my port" `},
+		{"plain", 0, true, "plain "},
+		//in a quote, the quote is escaped and closed
+		{"y port", '"', false, `y port" `},
+		{"t's", '\'', false, `t\'s' `},
+		{`a\"`, '"', false, `a\\\"" `},
+	}
+
+	for _, test := range tests {
+		if suffix := completionSuffix(test.rest, test.quote, test.fresh); suffix != test.suffix {
+			t.Errorf("%q in %q, fresh %v: %s, expected %s", test.rest, string(test.quote), test.fresh, suffix, test.suffix)
+		}
+	}
+}
@@ -0,0 +1,268 @@
+package completer
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+//An args struct declares the params of a command by its fields, in order. The "cli" tag of a field
+//tells about its param, in comma separated items:
+//
+//	name=vlan          name of the param, the field name in lower case if not given
+//	type=int           plain, selection, int or flag, told by the field type if not given
+//	range=1-4094       "min-max" of an int, values of a selection separated by '|', e.g. "access|trunk"
+//	help=vlan id       what the param is
+//	condition=- eq id  the param is given whenever the condition is met instead of in order
+//	optional           the param may be left out
+//	uniq               the param can not be given twice
+//
+//A flag is a keyword the user gives or not, for a bool field. A field of a slice type takes every value
+//given to a repeated param. Fields with the tag "-" and unexported fields are left out, e.g.
+//
+//	type vlanArgs struct {
+//		ID     int    `cli:"name=vlan,type=int,range=1-4094,help=vlan id"`
+//		Mode   string `cli:"type=selection,range=access|trunk,optional"`
+//		Tagged bool   `cli:"optional"`
+//	}
+
+//argField is a field of an args struct and the param it is given by
+type argField struct {
+	index int
+	param schemaParam
+}
+
+//the kinds of the fields which can take values
+func argKind(t reflect.Type) (kind reflect.Kind, ok bool) {
+
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String, reflect.Bool, reflect.Float32, reflect.Float64,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return t.Kind(), true
+	}
+
+	return t.Kind(), false
+}
+
+//reflect the fields of an args struct into params
+func argFields(t reflect.Type) (fields []argField, err error) {
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("args type %s is not a struct", t)
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+
+		f := t.Field(i)
+		tag := f.Tag.Get("cli")
+
+		if f.PkgPath != "" || tag == "-" {
+			continue
+		}
+
+		kind, ok := argKind(f.Type)
+		if !ok {
+			return nil, fmt.Errorf("field %s: type %s can not take a value", f.Name, f.Type)
+		}
+
+		p := schemaParam{NameDesc: paramNameDesc{Name: strings.ToLower(f.Name)}}
+		typeName := ""
+
+		for _, item := range strings.Split(tag, ",") {
+
+			key, value := strings.TrimSpace(item), ""
+			if eq := strings.Index(item, "="); eq >= 0 {
+				key, value = strings.TrimSpace(item[:eq]), strings.TrimSpace(item[eq+1:])
+			}
+
+			switch key {
+			case "":
+			case "name":
+				p.NameDesc.Name = value
+			case "type":
+				typeName = value
+			case "range":
+				p.Range = value
+			case "help":
+				p.NameDesc.desc = value
+			case "condition":
+				p.Condition = append(p.Condition, value)
+			case "optional":
+				p.Optional = true
+			case "uniq":
+				p.Unique = true
+			default:
+				return nil, fmt.Errorf("field %s: unknown tag item \"%s\"", f.Name, key)
+			}
+		}
+
+		if typeName == "" {
+			switch kind {
+			case reflect.Bool:
+				typeName = "flag"
+			case reflect.String, reflect.Float32, reflect.Float64:
+				typeName = "plain"
+			default:
+				typeName = "int"
+			}
+		}
+
+		switch strings.ToLower(typeName) {
+		case "plain":
+			p.Type = paramTypePlain
+		case "int", "integer":
+			p.Type = paramTypeInteger
+		case "selection":
+			var sels []interface{}
+			if r, ok := p.Range.(string); ok {
+				for _, s := range strings.Split(r, "|") {
+					sels = append(sels, s)
+				}
+			}
+			p.Type, p.Range = paramTypeSelection, sels
+		case "flag":
+			p.Type, p.Range = paramTypeSelection, []interface{}{p.NameDesc.Name}
+		default:
+			return nil, fmt.Errorf("field %s: unknown type \"%s\"", f.Name, typeName)
+		}
+
+		fields = append(fields, argField{index: i, param: p})
+	}
+
+	return
+}
+
+//set a field by the values given to its param
+func setArg(v reflect.Value, values []string) error {
+
+	if v.Kind() == reflect.Slice {
+		if len(values) == 0 {
+			return nil
+		}
+		slice := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i, value := range values {
+			if err := setArg(slice.Index(i), []string{value}); err != nil {
+				return err
+			}
+		}
+		v.Set(slice)
+		return nil
+	}
+
+	if v.Kind() == reflect.Bool {
+		v.SetBool(len(values) != 0)
+		return nil
+	}
+
+	if len(values) == 0 {
+		return nil
+	}
+
+	value := values[len(values)-1]
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("\"%s\" is not a number of %s", value, v.Type())
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(value, 10, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("\"%s\" is not a number of %s", value, v.Type())
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(value, v.Type().Bits())
+		if err != nil {
+			return fmt.Errorf("\"%s\" is not a number", value)
+		}
+		v.SetFloat(n)
+	}
+
+	return nil
+}
+
+//decode the values the line matched by the command is given into an args struct
+func decodeArgs(found *cmdMatch, fields []argField, args reflect.Value) error {
+
+	for _, f := range fields {
+		if err := setArg(args.Field(f.index), found.context.lookup(f.param.NameDesc.Name)); err != nil {
+			return fmt.Errorf("%s: %s", f.param.NameDesc.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+var stringWriterType = reflect.TypeOf((*io.StringWriter)(nil)).Elem()
+
+//Args declares the params of the command by the args struct the handler takes, the handler is called
+//with the values given in the command line, e.g.
+//
+//	completer.Command("vlan").Args(func(args *vlanArgs, w io.StringWriter) { ... }).Register()
+//
+//The handler is a func(args *T, w io.StringWriter) or func(args T, w io.StringWriter), T an args struct.
+//The params are added after the ones of Param.
+func (b *CommandBuilder) Args(handler interface{}) *CommandBuilder {
+
+	fn := reflect.ValueOf(handler)
+
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() != 2 || fn.Type().NumOut() != 0 || fn.Type().In(1) != stringWriterType {
+		b.err = fmt.Errorf("command %s: args handler must be a func(args *T, w io.StringWriter)", b.cmd.Name)
+		return b
+	}
+
+	t := fn.Type()
+
+	argsType, byPointer := t.In(0), false
+	if argsType.Kind() == reflect.Ptr {
+		argsType, byPointer = argsType.Elem(), true
+	}
+
+	fields, err := argFields(argsType)
+	if err != nil {
+		b.err = fmt.Errorf("command %s: %s", b.cmd.Name, err.Error())
+		return b
+	}
+
+	for _, f := range fields {
+		b.cmd.Params = append(b.cmd.Params, f.param)
+	}
+
+	b.handler.args = func(found *cmdMatch, w io.StringWriter) {
+
+		args := reflect.New(argsType)
+
+		if err := decodeArgs(found, fields, args.Elem()); err != nil {
+			if w != nil {
+				w.WriteString("% " + err.Error())
+			}
+			return
+		}
+
+		if !byPointer {
+			args = args.Elem()
+		}
+
+		//a nil writer is passed as it is, reflect.ValueOf would give an invalid value
+		writer := reflect.Zero(stringWriterType)
+		if w != nil {
+			writer = reflect.ValueOf(w)
+		}
+
+		fn.Call([]reflect.Value{args, writer})
+	}
+
+	return b
+}
@@ -0,0 +1,190 @@
+package completer
+
+import (
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ershixiongTQL/cli-ui/router"
+)
+
+type portArgs struct {
+	Port    string   `cli:"help=port name"`
+	VLAN    int      `cli:"name=vlan,range=1-4094,optional"`
+	Mode    string   `cli:"type=selection,range=access|trunk,optional"`
+	Tagged  bool     `cli:"optional"`
+	Allowed []uint16 `cli:"type=int,range=1-4094,condition={mode} eq trunk,optional"`
+	Ignored string   `cli:"-"`
+	hidden  string
+}
+
+func TestArgFields(t *testing.T) {
+
+	fields, err := argFields(reflect.TypeOf(portArgs{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []argField{
+		{0, schemaParam{NameDesc: paramNameDesc{"port", "port name"}, Type: paramTypePlain}},
+		{1, schemaParam{NameDesc: paramNameDesc{"vlan", ""}, Type: paramTypeInteger, Range: "1-4094", Optional: true}},
+		{2, schemaParam{NameDesc: paramNameDesc{"mode", ""}, Type: paramTypeSelection, Range: []interface{}{"access", "trunk"}, Optional: true}},
+		{3, schemaParam{NameDesc: paramNameDesc{"tagged", ""}, Type: paramTypeSelection, Range: []interface{}{"tagged"}, Optional: true}},
+		{4, schemaParam{NameDesc: paramNameDesc{"allowed", ""}, Type: paramTypeInteger, Range: "1-4094",
+			Condition: []string{"{mode} eq trunk"}, Optional: true}},
+	}
+
+	if !reflect.DeepEqual(fields, expected) {
+		t.Errorf("%+v\nexpected\n%+v", fields, expected)
+	}
+
+	uniq, err := argFields(reflect.TypeOf(struct {
+		Tag string `cli:"condition=*,optional,uniq"`
+	}{}))
+	if err != nil || len(uniq) != 1 || !uniq[0].param.Unique || uniq[0].param.Type != paramTypePlain {
+		t.Errorf("%+v, %v", uniq, err)
+	}
+
+	errs := []struct {
+		args interface{}
+		err  string
+	}{
+		{"", "args type string is not a struct"},
+		{struct{ C chan int }{}, "field C: type chan int can not take a value"},
+		{struct {
+			A string `cli:"name=a,bogus"`
+		}{}, `field A: unknown tag item "bogus"`},
+		{struct {
+			A string `cli:"type=list"`
+		}{}, `field A: unknown type "list"`},
+	}
+
+	for _, test := range errs {
+		if _, err := argFields(reflect.TypeOf(test.args)); err == nil || err.Error() != test.err {
+			t.Errorf("%T: %v, expected %s", test.args, err, test.err)
+		}
+	}
+}
+
+func TestArgs(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var got *portArgs
+	prefix := "args port"
+
+	err := Command(prefix).Args(func(args *portArgs, w io.StringWriter) { got = args }).Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		line string
+		args *portArgs //nil if the line does not match
+	}{
+		{"eth0", &portArgs{Port: "eth0"}},
+		{`"eth 0" access`, &portArgs{Port: "eth 0", Mode: "access"}},
+		{"eth0 10 tr tagged 5 6", &portArgs{Port: "eth0", VLAN: 10, Mode: "trunk", Tagged: true, Allowed: []uint16{5, 6}}},
+		{"eth0 trunk 7", &portArgs{Port: "eth0", Mode: "trunk", Allowed: []uint16{7}}},
+		{"", nil},
+		{"eth0 9999", nil},
+		{"eth0 access 5", nil},
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	for _, test := range tests {
+
+		got = nil
+
+		if handled := c.RunRegistered(prefix+" "+test.line, "", new(strings.Builder)); handled != (test.args != nil) {
+			t.Errorf("%q: handled %v", test.line, handled)
+		}
+
+		if !reflect.DeepEqual(got, test.args) {
+			t.Errorf("%q: decoded to %+v, expected %+v", test.line, got, test.args)
+		}
+	}
+
+	//the params of the args are in the schema
+
+	if usages, _ := c.Usage(prefix); !reflect.DeepEqual(usages, []string{prefix + " <port> [<1-4094>] [access|trunk] [tagged] [<1-4094>]..."}) {
+		t.Errorf("%q", usages)
+	}
+}
+
+func TestArgsByValue(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var got portArgs
+	var writer io.StringWriter = new(strings.Builder)
+
+	err := Command("args value").Args(func(args portArgs, w io.StringWriter) { got, writer = args, w }).Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	if !c.RunRegistered("args value eth1 20", "", new(strings.Builder)) || got.Port != "eth1" || got.VLAN != 20 {
+		t.Errorf("decoded to %+v", got)
+	}
+
+	//a nil writer is passed to the handler as it is
+	if !c.RunRegistered("args value eth2", "", nil) || got.Port != "eth2" || writer != nil {
+		t.Errorf("decoded to %+v, writer %v", got, writer)
+	}
+}
+
+//the values are those of the command the line matches, not of another one its keywords are the start of
+func TestArgsOverlap(t *testing.T) {
+
+	isolateRegistered(t)
+
+	var ports []portArgs
+	var shown []string
+
+	err := Command("args show").Param(Plain("what", "").Optional()).Handler(func(input router.Input, w io.StringWriter) {
+		shown = append(shown, input.GetRaw())
+	}).Register()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Command("args show port").Args(func(args *portArgs, w io.StringWriter) { ports = append(ports, *args) }).Register(); err != nil {
+		t.Fatal(err)
+	}
+
+	c := newTestCompleter(t, testSchema)
+
+	c.RunRegistered("ar sh po eth0 10", "", new(strings.Builder))
+	c.RunRegistered("args show version", "", new(strings.Builder))
+
+	if !reflect.DeepEqual(ports, []portArgs{{Port: "eth0", VLAN: 10}}) || !reflect.DeepEqual(shown, []string{"args show version"}) {
+		t.Errorf("ran port with %+v, show with %q", ports, shown)
+	}
+}
+
+func TestArgsHandlerType(t *testing.T) {
+
+	isolateRegistered(t)
+
+	for _, handler := range []interface{}{
+		nil,
+		"handler",
+		func(args *portArgs) {},
+		func(args *portArgs, w io.Writer) {},
+		func(args *portArgs, w io.StringWriter) error { return nil },
+		func(args *int, w io.StringWriter) {},
+	} {
+		if err := Command("args bad").Args(handler).Register(); err == nil {
+			t.Errorf("%T: registered", handler)
+		}
+	}
+
+	if prefixes := registeredPrefixes(); len(prefixes) != 0 {
+		t.Errorf("registered %q", prefixes)
+	}
+}
@@ -0,0 +1,331 @@
+package completer
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+)
+
+//HelpCommand is the keyword of the built-in command telling the usage of the others, e.g. "help show interface".
+//It is left out if a command of the schema starts with the same keyword. Its abbreviations give way to the
+//ones of the schema commands, "h" stays "history" if the schema has a history command.
+const HelpCommand = "help"
+
+//SetHelpCommand tells if the built-in help command is added to the schema, it is by default.
+//It takes effect when the schema is set up or reloaded next.
+func (s *Completer) SetHelpCommand(enabled bool) {
+	s.lock.Lock()
+	s.noHelp = !enabled
+	s.lock.Unlock()
+}
+
+//the built-in help command, its words after the keyword are the ones of the commands asked for
+func helpCommand() schemaCommand {
+	return schemaCommand{
+		Name:    "help",
+		Prefix:  HelpCommand,
+		Comment: "Show the usage of commands",
+		Params: []schemaParam{{
+			NameDesc:  paramNameDesc{Name: "command", desc: "keywords of a command"},
+			Type:      paramTypePlain,
+			Condition: []string{"*"},
+			Optional:  true,
+		}},
+		source:  "built-in",
+		builtin: true,
+	}
+}
+
+//does a command start with the keyword
+func (t *schemaTop) takesKeyword(keyword string) bool {
+
+	for i := range t.Commands {
+		if words := strings.Fields(t.Commands[i].Prefix); len(words) != 0 && strings.EqualFold(words[0], keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+//what a param is, in short, e.g. "<name>", "<1-4094>", "brief|detail"
+func (p *schemaParam) syntax() string {
+
+	switch p.Type {
+	case paramTypeSelection:
+		sels, _, _ := rangeDecodeSelection(p.Range)
+		return strings.Join(stringsUniqOrdered(sels), "|")
+	default:
+		return p.getHelps()[0].whatToInput
+	}
+}
+
+//can the param be given again right after it is given
+func (p *schemaParam) repeatable() bool {
+
+	if p.Unique || len(p.Condition) == 0 {
+		return false
+	}
+
+	if !p.relativeCondition() {
+		return true
+	}
+
+	context := new(cmdContext)
+	context.init()
+	context.append(p.NameDesc.Name, p.syntax())
+
+	return p.conditionCheck(context)
+}
+
+//is the param given on conditions, rather than anywhere or in order
+func (p *schemaParam) conditional() bool {
+
+	for _, c := range p.Condition {
+		if strings.TrimSpace(c) != "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+//keep the order, drop the duplicates and the empty ones
+func stringsUniqOrdered(all []string) (result []string) {
+
+	seen := make(map[string]bool)
+
+	for _, s := range all {
+		if s != "" && !seen[s] {
+			seen[s] = true
+			result = append(result, s)
+		}
+	}
+
+	return
+}
+
+//usage line of the command, e.g. "show interface <name> [brief|detail]". The params which can be
+//left out are in [], the ones required when their conditions are met are in (), a choice of keywords
+//required anyway is in {}. "..." follows a param which can be repeated.
+func (c *schemaCommand) usage() string {
+
+	words := strings.Fields(c.Prefix)
+
+	for _, p := range append(append([]*schemaParam{}, c.staticParams...), c.dynamParams...) {
+
+		syntax := p.syntax()
+
+		switch {
+		case p.Optional:
+			syntax = "[" + syntax + "]"
+		case p.conditional():
+			syntax = "(" + syntax + ")"
+		case strings.Contains(syntax, "|"):
+			syntax = "{" + syntax + "}"
+		}
+
+		if p.repeatable() {
+			syntax += "..."
+		}
+
+		words = append(words, syntax)
+	}
+
+	return strings.Join(words, " ")
+}
+
+//the usage and the params of the command, for the help command
+func (c *schemaCommand) helpText() string {
+
+	doc := c.doc()
+
+	var buf bytes.Buffer
+	buf.WriteString(doc.synopsis + "\n")
+
+	if doc.comment != "" {
+		buf.WriteString("\n  " + doc.comment + "\n")
+	}
+
+	if len(doc.params) != 0 {
+
+		buf.WriteString("\n")
+		tw := tabwriter.NewWriter(&buf, 8, 8, 4, ' ', 0)
+
+		for _, p := range doc.params {
+
+			help := p.usage()
+			if p.help != "" {
+				help = p.help + ", " + help
+			}
+
+			fmt.Fprintf(tw, "  %s\t%s\t%s\n", p.name, p.kind, help)
+
+			for _, v := range p.values {
+				if v.help != "" {
+					fmt.Fprintf(tw, "    %s\t\t%s\n", v.value, v.help)
+				}
+			}
+		}
+
+		tw.Flush()
+	}
+
+	return buf.String()
+}
+
+//the commands of which the prefixes start with the words, abbreviations accepted. A word equal to
+//a keyword leaves out the commands it is only an abbreviation for.
+func (t *schemaTop) lookup(words []string) (found []*schemaCommand) {
+
+	for i := range t.Commands {
+
+		keywords := strings.Fields(t.Commands[i].Prefix)
+		if len(words) > len(keywords) {
+			continue
+		}
+
+		matched := true
+		for j, w := range words {
+			if !strings.HasPrefix(strings.ToLower(keywords[j]), strings.ToLower(w)) {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			found = append(found, &t.Commands[i])
+		}
+	}
+
+	for j, w := range words {
+
+		var exact []*schemaCommand
+		for _, c := range found {
+			if strings.EqualFold(strings.Fields(c.Prefix)[j], w) {
+				exact = append(exact, c)
+			}
+		}
+
+		if len(exact) != 0 {
+			found = exact
+		}
+	}
+
+	return
+}
+
+func unknownCommand(command string) error {
+	return fmt.Errorf("unknown command \"%s\"", command)
+}
+
+//Usage returns the usage lines of the commands starting with the keywords given, e.g. "show int" for
+//"show interface <name> [brief|detail]". Every command is given for "".
+func (s *Completer) Usage(command string) (usages []string, err error) {
+
+	found := s.current().lookup(CmdlineField(command).Strings())
+	if len(found) == 0 {
+		return nil, unknownCommand(command)
+	}
+
+	for _, c := range found {
+		usages = append(usages, c.usage())
+	}
+
+	return
+}
+
+//Help returns the usage, the comment and the params of the commands starting with the keywords given
+func (s *Completer) Help(command string) (help string, err error) {
+	return s.current().help(CmdlineField(command).Strings())
+}
+
+func (t *schemaTop) help(words []string) (help string, err error) {
+
+	found := t.lookup(words)
+	if len(found) == 0 {
+		return "", unknownCommand(strings.Join(words, " "))
+	}
+
+	//many commands in short
+	if len(found) > 1 {
+		var buf bytes.Buffer
+		tw := tabwriter.NewWriter(&buf, 16, 8, 4, ' ', 0)
+		for _, c := range found {
+			fmt.Fprintf(tw, "%s\t%s\n", c.usage(), c.Comment)
+		}
+		tw.Flush()
+		return buf.String(), nil
+	}
+
+	return found[0].helpText(), nil
+}
+
+//the words of a command line of the built-in help after its keyword, if the keyword is done
+func (c *schemaCommand) helpWords(segs []string, next bool) (words []string, ok bool) {
+
+	prefixLen := len(strings.Fields(c.Prefix))
+	if len(segs) < prefixLen || len(segs) == prefixLen && !next {
+		return nil, false
+	}
+
+	words = segs
+	_, _, ok = c.prefixComplete(&words, next)
+
+	return
+}
+
+//completions of the words of the built-in help, the keywords of the commands
+func (t *schemaTop) helpComplete(words []string, next bool) (completions []Candidate) {
+
+	for i := range t.Commands {
+		if inputs := append([]string{}, words...); !t.Commands[i].builtin {
+			if comp, keyword, match := t.Commands[i].prefixComplete(&inputs, next); match && comp != "" {
+				completions = append(completions, Candidate{Text: comp, Value: keyword, Description: strings.Title(t.Commands[i].Name), Kind: CandidateKeyword})
+			}
+		}
+	}
+
+	return
+}
+
+func (t *schemaTop) helpHelps(words []string, next bool) (helps []cmdHelp) {
+
+	for i := range t.Commands {
+		if inputs := append([]string{}, words...); !t.Commands[i].builtin {
+			if _, fulls, match := t.Commands[i].prefixComplete(&inputs, next); match && fulls != "" {
+				helps = append(helps, cmdHelp{whatToInput: fulls, info: strings.Title(t.Commands[i].Name), kind: CandidateKeyword})
+			}
+		}
+	}
+
+	return
+}
+
+//RunBuiltin runs a command line if it is of a built-in command, the help command. The line is expected to be validated.
+func (s *Completer) RunBuiltin(input string, w io.StringWriter) (handled bool, err error) {
+
+	schema := s.current()
+	segs := CmdlineField(input).Strings()
+
+	if len(segs) == 0 {
+		return false, nil
+	}
+
+	found := schema.lookup(segs[:1])
+	if len(found) != 1 || !found[0].builtin {
+		return false, nil
+	}
+
+	help, err := schema.help(segs[1:])
+	if err != nil {
+		w.WriteString("% " + err.Error())
+		return true, err
+	}
+
+	w.WriteString(strings.TrimSuffix(help, "\n"))
+
+	return true, nil
+}
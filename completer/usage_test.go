@@ -0,0 +1,150 @@
+package completer
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const conditionalSchema = `{"commands": [
+ {"name": "port", "prefix": "port",
+  "param": [
+   {"name": "mode", "type": "selection", "range": ["access", "trunk"]},
+   {"name": "vlan", "type": "integer", "range": "1-4094", "condition": ["{-} eq access"], "uniq": true},
+   {"name": "allowed", "type": "plain", "condition": ["{mode} eq trunk"]},
+   {"name": "never", "type": "plain", "condition": ["{mode} eq bogus"]}
+  ]}
+]}`
+
+func TestUsage(t *testing.T) {
+
+	tests := []struct {
+		schema  string
+		command string
+		usages  []string
+	}{
+		{testSchema, "show", []string{"show interface <name> [brief|detail]", "show vlan <1-4094>"}},
+		{testSchema, "sh int", []string{"show interface <name> [brief|detail]"}},
+		{testSchema, "set", []string{"set {speed|status|description} <value> [force] [<tag>]"}},
+		{testSchema, "help", []string{"help [<command>]..."}},
+		//required when the conditions are met, not optional
+		{conditionalSchema, "port", []string{"port {access|trunk} (<1-4094>) (<allowed>)... (<never>)..."}},
+	}
+
+	for _, test := range tests {
+
+		c := newTestCompleter(t, test.schema)
+
+		usages, err := c.Usage(test.command)
+		if err != nil || !reflect.DeepEqual(usages, test.usages) {
+			t.Errorf("%q: %q, %v, expected %q", test.command, usages, err, test.usages)
+		}
+	}
+}
+
+//a param out of [] in the usage line can not be left out
+func TestUsageAgreesWithValidate(t *testing.T) {
+
+	c := newTestCompleter(t, conditionalSchema)
+
+	if err := c.Validate("port access"); err == nil {
+		t.Errorf("port access: validated without the vlan")
+	}
+
+	if err := c.Validate("port access 10"); err != nil {
+		t.Errorf("port access 10: %v", err)
+	}
+}
+
+func TestHelp(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	help, err := c.Help("show int")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"show interface <name> [brief|detail]", "Show interfaces", "interface name", "brief info"} {
+		if !strings.Contains(help, want) {
+			t.Errorf("help of show interface without %q:\n%s", want, help)
+		}
+	}
+
+	if _, err := c.Help("nothing"); err == nil {
+		t.Errorf("help of an unknown command")
+	}
+
+	var out strings.Builder
+	if handled, err := c.RunBuiltin("help shu", &out); !handled || err != nil || !strings.HasPrefix(out.String(), "shutdown") {
+		t.Errorf("help shu: %v, %v, %q", handled, err, out.String())
+	}
+
+	if handled, _ := c.RunBuiltin("show vlan 1", &out); handled {
+		t.Errorf("show vlan 1 taken as built-in")
+	}
+}
+
+//the abbreviations of the schema commands are not taken by the built-in help command
+func TestHelpCommandAbbreviation(t *testing.T) {
+
+	c := newTestCompleter(t, `{"commands": [{"name": "history", "prefix": "history"}, {"name": "show", "prefix": "show"}]}`)
+
+	for input, expanded := range map[string]string{"h": "history", "hi": "history", "he": "help", "h show": "help show"} {
+		if err := c.Validate(input); err != nil {
+			t.Errorf("%q: %v", input, err)
+			continue
+		}
+		if line, err := c.Expand(input); err != nil || line != expanded {
+			t.Errorf("%q: expanded to %q, %v, expected %q", input, line, err, expanded)
+		}
+	}
+}
+
+func TestHelpCommandDisabled(t *testing.T) {
+
+	c := new(Completer)
+	c.SetHelpCommand(false)
+	if err := c.SetupBytes([]byte(testSchema), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate("help show"); err == nil {
+		t.Errorf("help show taken with the help command turned off")
+	}
+
+	c.SetHelpCommand(true)
+	if err := c.Reload(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Validate("help show"); err != nil {
+		t.Errorf("help show: %v", err)
+	}
+}
+
+//the built-in commands are not in the reference of the commands nor in the graphs
+func TestHelpCommandNotDocumented(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	var doc strings.Builder
+	if err := c.WriteDoc(&doc, DocMarkdown, "Commands"); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(doc.String(), "## help") {
+		t.Errorf("the help command in the doc:\n%s", doc.String())
+	}
+
+	var graph strings.Builder
+	if err := c.WriteGraph(&graph, GraphDOT, ""); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(graph.String(), "help") {
+		t.Errorf("the help command in the graph:\n%s", graph.String())
+	}
+
+	if err := c.WriteGraph(new(strings.Builder), GraphDOT, "help"); err == nil {
+		t.Errorf("graph of the help command")
+	}
+}
@@ -5,19 +5,46 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"unicode"
 )
 
-var rawStrBounds = []byte{'"', '\''}
-
-//TODO: Add struct CmdLineFields
+//CmdLineFields are the fields of a command line, split the way a shell does: by spaces, which a field
+//keeps if they are quoted or escaped by '\'. A field can be made of quoted and unquoted parts next to
+//each other, e.g. name="a b" is the field `name=a b`.
+//
+//Out of quotes, '\' escapes a space, a quote or another '\'. In quotes, it escapes the quote or another '\'.
+//It is taken as it is otherwise.
 type CmdLineFields struct {
-	segs []CmdLineSeg
+	segs        []CmdLineSeg
+	unclosed    byte //the quote left open at the end of line, 0 if none
+	unclosedPos int  //byte offset of the quote left open
+}
+
+//Unclosed tells if the line ends inside a quoted segment
+func (f *CmdLineFields) Unclosed() bool {
+	return f.unclosed != 0
+}
+
+//Err returns a *SyntaxError pointing at the quote left open if the line ends inside quotes, nil otherwise
+func (f *CmdLineFields) Err() error {
+
+	if f.unclosed == 0 {
+		return nil
+	}
+
+	last := f.segs[len(f.segs)-1]
+
+	return &SyntaxError{Kind: SyntaxUnclosedQuote, Index: len(f.segs) - 1, Token: last.raw, Pos: f.unclosedPos}
 }
 
 func (f *CmdLineFields) Count() int {
 	return len(f.segs)
 }
 
+func (f *CmdLineFields) Segs() []CmdLineSeg {
+	return f.segs
+}
+
 func (f *CmdLineFields) Bytes() (bytes [][]byte) {
 	for _, s := range f.segs {
 		bytes = append(bytes, []byte(s.content))
@@ -32,157 +59,199 @@ func (f CmdLineFields) Strings() (strs []string) {
 	return
 }
 
+//Offsets are the byte offsets of the fields in the line
+func (f *CmdLineFields) Offsets() (offsets []int) {
+	for _, s := range f.segs {
+		offsets = append(offsets, s.start)
+	}
+	return
+}
+
 func (f *CmdLineFields) Append(seg CmdLineSeg) {
 	f.segs = append(f.segs, seg)
 }
 
+//CmdLineSeg is a field of a command line
 type CmdLineSeg struct {
 	content string
-	quoted  bool
+	quoted  bool   //a part of it is quoted
+	start   int    //byte offset of the field in the line
+	end     int    //byte offset after the field
+	raw     string //the field as it is in the line
 }
 
 func (s *CmdLineSeg) IsQuoted() bool {
 	return s.quoted
 }
 
+//UnquotString is the content of the field, without the quotes and the escapes
 func (s *CmdLineSeg) UnquotString() string {
 	return s.content
 }
 
+//String is the field the way it can be given in a command line, quoted if needed
 func (s *CmdLineSeg) String() string {
-	if !s.quoted {
-		return s.UnquotString()
-	} else {
-		//TODO:
-		return ""
-	}
+	return Quote(s.content)
 }
 
-//Split a command line into fields
-func CmdlineField(str string) (fields CmdLineFields) {
+//Raw is the field as it is in the line
+func (s *CmdLineSeg) Raw() string {
+	return s.raw
+}
 
-	var currSegs = []byte{}
-	var currQuot string
-	var currSlash = false
+//Offset is the byte offset of the field in the line
+func (s *CmdLineSeg) Offset() int {
+	return s.start
+}
 
-	var preField = []CmdLineSeg{}
+//End is the byte offset after the field in the line
+func (s *CmdLineSeg) End() int {
+	return s.end
+}
 
-	for _, c := range []byte(str) {
+func isFieldSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
 
-		if currSlash {
-			currSegs = append(currSegs, c)
-		} else {
+func isQuote(c byte) bool {
+	return c == '"' || c == '\''
+}
 
-			if currQuot != "" {
-				currSegs = append(currSegs, c)
-				if c == currQuot[0] {
-					seg := CmdLineSeg{content: string(currSegs), quoted: true}
-					preField = append(preField, seg)
-					currSegs = []byte{}
-					currQuot = ""
-				}
-			} else {
+//Split a command line into fields
+func CmdlineField(str string) (fields CmdLineFields) {
 
-				for _, q := range rawStrBounds {
-					if c == q {
-						currQuot = string(q)
-						break
-					}
-				}
+	var content []byte
+	var quote byte
+	inField, quoted, start := false, false, 0
 
-				if currQuot != "" {
-					if len(currSegs) != 0 {
-						seg := CmdLineSeg{content: string(currSegs), quoted: false}
-						preField = append(preField, seg)
-					}
-					currSegs = []byte{c}
-				} else {
-					currSegs = append(currSegs, c)
-				}
+	flush := func(end int) {
+		if inField {
+			fields.Append(CmdLineSeg{content: string(content), quoted: quoted, start: start, end: end, raw: str[start:end]})
+		}
+		content, inField, quoted = nil, false, false
+	}
+
+	for i := 0; i < len(str); i++ {
+
+		c := str[i]
+
+		if quote != 0 {
+			switch {
+			case c == '\\' && i+1 < len(str) && (str[i+1] == quote || str[i+1] == '\\'):
+				i++
+				content = append(content, str[i])
+			case c == quote:
+				quote = 0
+			default:
+				content = append(content, c)
 			}
+			continue
+		}
 
+		if isFieldSpace(c) {
+			flush(i)
+			continue
 		}
 
-		if c == '\\' {
-			currSlash = !currSlash
-		} else {
-			currSlash = false
+		if !inField {
+			inField, start = true, i
+		}
+
+		switch {
+		case c == '\\' && i+1 < len(str) && (isFieldSpace(str[i+1]) || isQuote(str[i+1]) || str[i+1] == '\\'):
+			i++
+			content = append(content, str[i])
+		case isQuote(c):
+			quote, quoted = c, true
+			fields.unclosedPos = i
+		default:
+			content = append(content, c)
 		}
 	}
 
-	if len(currSegs) != 0 {
-		preField = append(preField, CmdLineSeg{content: string(currSegs), quoted: false})
+	if quote != 0 {
+		fields.unclosed = quote
 	}
 
-	// result := []string{}
-
-	for _, seg := range preField {
-		var content string
-		if seg.quoted {
-			if len(seg.content) <= 2 {
-				content = ""
-			} else {
-				content = seg.content[1 : len(seg.content)-1]
-				for _, q := range rawStrBounds {
-					content = strings.ReplaceAll(content, string([]byte{'\\', q}), string(q))
-				}
-			}
-			fields.Append(CmdLineSeg{content: content, quoted: true})
+	flush(len(str))
 
-		} else {
-			content = seg.content
-			for _, q := range rawStrBounds {
-				content = strings.ReplaceAll(content, string([]byte{'\\', q}), string(q))
-			}
-			for _, s := range strings.Fields(content) {
-				fields.Append(CmdLineSeg{content: s, quoted: false})
-			}
-		}
+	return
+}
 
+//Quote returns the word the way it is given in a command line, CmdlineField takes it back as one field.
+//It is quoted only if needed.
+func Quote(word string) string {
+
+	if word != "" && !strings.ContainsAny(word, " \t\r\n\"'\\") {
+		return word
 	}
 
+	return "\"" + strings.NewReplacer("\\", "\\\\", "\"", "\\\"").Replace(word) + "\""
+}
+
+var heredocMarker = regexp.MustCompile(`<<\s*([A-Za-z0-9_]+)\s*$`)
+
+//SplitHeredoc splits a heredoc style input, the first line of which ends with "<<TERMINATOR":
+//
+//	crypto import <<EOF
+//	...body lines...
+//	EOF
+//
+//The command is the first line without the marker. If the terminator line has not been
+//given yet, the body is what is there so far and complete is false.
+//isHeredoc is false if the input does not start a heredoc.
+func SplitHeredoc(input string) (command, body string, isHeredoc, complete bool) {
+
+	lines := strings.Split(input, "\n")
+
+	found := heredocMarker.FindStringSubmatchIndex(lines[0])
+	if found == nil {
+		return input, "", false, false
+	}
+
+	command = strings.TrimRightFunc(lines[0][:found[0]], unicode.IsSpace)
+	terminator := lines[0][found[2]:found[3]]
+	isHeredoc = true
+
+	for i, l := range lines[1:] {
+		if strings.TrimSpace(l) == terminator {
+			return command, strings.Join(lines[1:i+1], "\n"), true, true
+		}
+	}
+
+	body = strings.Join(lines[1:], "\n")
 	return
 }
 
 func LongestCommonPrefix(strs []string) (prefix string) {
 
-	var prefixLen int = 1
-
 	if len(strs) == 0 {
 		return ""
 	} else if len(strs) == 1 {
 		return strs[0]
 	}
 
-	cnt := 0
+	//compare in runes, so that a multi-byte character is never cut in half
+	first := []rune(strs[0])
+	prefixLen := len(first)
 
-	for {
-		cnt = 0
-		for _, s := range strs {
+	for _, s := range strs[1:] {
 
-			if len(s) < prefixLen {
-				break
-			}
+		runes := []rune(s)
+
+		if len(runes) < prefixLen {
+			prefixLen = len(runes)
+		}
 
-			if !strings.HasPrefix(s, strs[0][:prefixLen]) {
+		for i := 0; i < prefixLen; i++ {
+			if runes[i] != first[i] {
+				prefixLen = i
 				break
 			}
-
-			cnt++
 		}
-		if cnt != len(strs) {
-			break
-		}
-		prefixLen++
 	}
 
-	prefixLen--
-
-	if prefixLen == 0 {
-		return ""
-	} else {
-		return strs[0][:prefixLen]
-	}
+	return string(first[:prefixLen])
 }
 
 func RangeNumParse(_raw string, do func(uint32)) (err error) {
@@ -0,0 +1,175 @@
+package completer
+
+import (
+	"reflect"
+	"testing"
+)
+
+//a field as it is expected to be split
+type testSeg struct {
+	text       string //without the quotes and the escapes
+	raw        string
+	start, end int
+}
+
+func TestCmdlineField(t *testing.T) {
+
+	tests := []struct {
+		input string
+		segs  []testSeg
+	}{
+		{"", nil},
+		{"   ", nil},
+		{"  show  vlan\t10 ", []testSeg{{"show", "show", 2, 6}, {"vlan", "vlan", 8, 12}, {"10", "10", 13, 15}}},
+		//quoted and unquoted parts next to each other are one field
+		{`name="a b" x`, []testSeg{{"name=a b", `name="a b"`, 0, 10}, {"x", "x", 11, 12}}},
+		{`'a b'"c"`, []testSeg{{"a bc", `'a b'"c"`, 0, 8}}},
+		{`""`, []testSeg{{"", `""`, 0, 2}}},
+		//escapes out of quotes
+		{`a\ b c`, []testSeg{{"a b", `a\ b`, 0, 4}, {"c", "c", 5, 6}}},
+		{`\"a\\`, []testSeg{{`"a\`, `\"a\\`, 0, 5}}},
+		{`x\y a\`, []testSeg{{`x\y`, `x\y`, 0, 3}, {`a\`, `a\`, 4, 6}}},
+		//escapes in quotes
+		{`"a \"q\" \\" z`, []testSeg{{`a "q" \`, `"a \"q\" \\"`, 0, 12}, {"z", "z", 13, 14}}},
+		{`'a\'b' "\n"`, []testSeg{{"a'b", `'a\'b'`, 0, 6}, {`\n`, `"\n"`, 7, 11}}},
+		//the offsets are in bytes
+		{"été x", []testSeg{{"été", "été", 0, 5}, {"x", "x", 6, 7}}},
+	}
+
+	for _, test := range tests {
+
+		fields := CmdlineField(test.input)
+
+		var segs []testSeg
+		for _, s := range fields.Segs() {
+			segs = append(segs, testSeg{s.UnquotString(), s.Raw(), s.Offset(), s.End()})
+			if s.Raw() != test.input[s.Offset():s.End()] {
+				t.Errorf("%q: raw %q is not the line at %d-%d", test.input, s.Raw(), s.Offset(), s.End())
+			}
+		}
+
+		if !reflect.DeepEqual(segs, test.segs) {
+			t.Errorf("%q: split into %+v, expected %+v", test.input, segs, test.segs)
+		}
+
+		if fields.Unclosed() || fields.Err() != nil {
+			t.Errorf("%q: unclosed, %v", test.input, fields.Err())
+		}
+	}
+}
+
+func TestCmdlineFieldUnclosed(t *testing.T) {
+
+	tests := []struct {
+		input string
+		segs  []testSeg
+		index int
+		pos   int
+	}{
+		{`show "eth 0`, []testSeg{{"show", "show", 0, 4}, {"eth 0", `"eth 0`, 5, 11}}, 1, 5},
+		{`a 'b'c"d`, []testSeg{{"a", "a", 0, 1}, {"bcd", `'b'c"d`, 2, 8}}, 1, 6},
+		{`x "a \"`, []testSeg{{"x", "x", 0, 1}, {`a "`, `"a \"`, 2, 7}}, 1, 2},
+		{`'`, []testSeg{{"", `'`, 0, 1}}, 0, 0},
+	}
+
+	for _, test := range tests {
+
+		fields := CmdlineField(test.input)
+
+		var segs []testSeg
+		for _, s := range fields.Segs() {
+			segs = append(segs, testSeg{s.UnquotString(), s.Raw(), s.Offset(), s.End()})
+		}
+
+		if !reflect.DeepEqual(segs, test.segs) {
+			t.Errorf("%q: split into %+v, expected %+v", test.input, segs, test.segs)
+		}
+
+		if !fields.Unclosed() {
+			t.Errorf("%q: not unclosed", test.input)
+		}
+
+		syntaxErr, ok := fields.Err().(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: %v, expected a *SyntaxError", test.input, fields.Err())
+			continue
+		}
+
+		if syntaxErr.Kind != SyntaxUnclosedQuote || syntaxErr.Index != test.index || syntaxErr.Pos != test.pos ||
+			syntaxErr.Token != test.segs[test.index].raw {
+			t.Errorf("%q: %s at %d, offset %d, token %q, expected an unclosed quote at %d, offset %d", test.input,
+				syntaxErr.Kind, syntaxErr.Index, syntaxErr.Pos, syntaxErr.Token, test.index, test.pos)
+		}
+	}
+}
+
+func TestQuote(t *testing.T) {
+
+	tests := []struct {
+		word   string
+		quoted string
+	}{
+		{"plain", "plain"},
+		{"été", "été"},
+		{"", `""`},
+		{"a b", `"a b"`},
+		{"tab\there", "\"tab\there\""},
+		{`say "hi"`, `"say \"hi\""`},
+		{"it's", `"it's"`},
+		{`back\slash`, `"back\\slash"`},
+		{`\`, `"\\"`},
+		{`"`, `"\""`},
+	}
+
+	for _, test := range tests {
+
+		quoted := Quote(test.word)
+		if quoted != test.quoted {
+			t.Errorf("%q: quoted as %s, expected %s", test.word, quoted, test.quoted)
+		}
+
+		//taken back as the same single field
+		fields := CmdlineField(quoted)
+		if fields.Count() != 1 || fields.Err() != nil {
+			t.Errorf("%q: %s split into %q, %v", test.word, quoted, fields.Strings(), fields.Err())
+			continue
+		}
+
+		seg := fields.Segs()[0]
+		if seg.UnquotString() != test.word || seg.String() != quoted || seg.End() != len(quoted) {
+			t.Errorf("%q: %s taken back as %q", test.word, quoted, seg.UnquotString())
+		}
+	}
+}
+
+func TestSplitHeredoc(t *testing.T) {
+
+	tests := []struct {
+		input     string
+		command   string
+		body      string
+		isHeredoc bool
+		complete  bool
+	}{
+		{"show vlan", "show vlan", "", false, false},
+		{"echo a<<EOF b", "echo a<<EOF b", "", false, false},
+		{"import <<EOF", "import", "", true, false},
+		{"import <<EOF\nline 1\nline 2", "import", "line 1\nline 2", true, false},
+		{"import <<EOF\nline 1\nline 2\nEOF", "import", "line 1\nline 2", true, true},
+		{"import <<EOF\nEOF", "import", "", true, true},
+		//the marker may have spaces around, so may the terminator line
+		{"import  << END \nline\n  END \nafter", "import", "line", true, true},
+		//the terminator is the whole line
+		{"import <<EOF\nEOFX\n EOF", "import", "EOFX", true, true},
+		{"<<EOF\nx\nEOF", "", "x", true, true},
+	}
+
+	for _, test := range tests {
+
+		command, body, isHeredoc, complete := SplitHeredoc(test.input)
+		if command != test.command || body != test.body || isHeredoc != test.isHeredoc || complete != test.complete {
+			t.Errorf("%q: split into %q, %q, %v, %v, expected %q, %q, %v, %v", test.input, command, body, isHeredoc, complete,
+				test.command, test.body, test.isHeredoc, test.complete)
+		}
+	}
+}
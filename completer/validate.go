@@ -0,0 +1,229 @@
+package completer
+
+import (
+	"fmt"
+	"strings"
+)
+
+type SyntaxErrorKind int
+
+const (
+	SyntaxInvalidInput SyntaxErrorKind = iota
+	SyntaxIncomplete
+	SyntaxUnclosedQuote
+	SyntaxAmbiguous
+)
+
+func (k SyntaxErrorKind) String() string {
+	switch k {
+	case SyntaxInvalidInput:
+		return "invalid input"
+	case SyntaxIncomplete:
+		return "incomplete"
+	case SyntaxUnclosedQuote:
+		return "unclosed quote"
+	case SyntaxAmbiguous:
+		return "ambiguous"
+	default:
+		return fmt.Sprintf("SyntaxErrorKind(%d)", int(k))
+	}
+}
+
+//SyntaxError tells why a command line does not match the schema
+type SyntaxError struct {
+	Kind        SyntaxErrorKind
+	Index       int      //index of the offending token, or the number of tokens for SyntaxIncomplete
+	Token       string   //the offending token, "" for SyntaxIncomplete
+	Pos         int      //byte offset of the offending token in the line, of the quote for SyntaxUnclosedQuote, -1 for SyntaxIncomplete
+	Expected    []string //what could have been given instead
+	Suggestions []string //the keywords or values the offending token may have been meant as
+	Candidates  []string //the keywords an ambiguous token stands for
+}
+
+func (e *SyntaxError) Error() (msg string) {
+
+	switch e.Kind {
+	case SyntaxIncomplete:
+		msg = "% Incomplete command."
+	case SyntaxUnclosedQuote:
+		msg = "% Unclosed quote at '^' marker."
+	case SyntaxAmbiguous:
+		msg = "% Ambiguous command at '^' marker."
+	default:
+		msg = "% Invalid input detected at '^' marker."
+	}
+
+	if len(e.Expected) != 0 {
+		msg += "\n% Expected: " + strings.Join(e.Expected, ", ")
+	}
+
+	if len(e.Candidates) != 0 {
+		msg += "\n% Could be: " + strings.Join(e.Candidates, ", ")
+	}
+
+	if len(e.Suggestions) != 0 {
+		msg += "\n% Did you mean: " + strings.Join(e.Suggestions, ", ") + "?"
+	}
+
+	return
+}
+
+//Offset is the byte offset in the line a '^' marker should point at, -1 if there is no such position
+func (e *SyntaxError) Offset() int {
+	return e.Pos
+}
+
+//what could be given after the segments
+func (t *schemaTop) expected(segs []string) (expected []string) {
+
+	for _, h := range t.expectedHelps(segs) {
+		expected = append(expected, h.whatToInput)
+	}
+
+	return stringsUniq(expected)
+}
+
+func (t *schemaTop) expectedHelps(segs []string) (helps []cmdHelp) {
+
+	for _, cmd := range t.Commands {
+		helps = append(helps, cmd.help(segs, true)...)
+	}
+
+	return
+}
+
+//is the line executable as far as the command is concerned: the prefix is complete,
+//every static param which is not optional has been given, and no dynamic param which
+//is not optional is waiting for its value
+func (m *cmdMatch) complete() bool {
+
+	if len(m.words) < len(strings.Fields(m.command.Prefix)) {
+		return false
+	}
+
+	for _, p := range m.command.staticParams {
+		if !p.Optional && m.context.count(p.NameDesc.Name) == 0 {
+			return false
+		}
+	}
+
+	for _, next := range m.command.nextParams(m.param, m.staticParamPos, m.context) {
+		p := next.param
+		if p.Optional || len(p.Condition) == 0 {
+			continue
+		}
+		//a param following the previous one must be given right after it
+		if m.context.count(p.NameDesc.Name) == 0 || p.relativeCondition() {
+			return false
+		}
+	}
+
+	return true
+}
+
+//Validate checks a command line against the schema before it is executed. Abbreviations are accepted.
+//It returns a *SyntaxError telling at which token the line goes wrong, or that the line is incomplete.
+//The keywords or values a wrong token may have been meant as, a typo of, are suggested.
+func (s *Completer) Validate(input string) error {
+
+	schema := s.current()
+	err := schema.validate(input)
+
+	if syntaxErr, ok := err.(*SyntaxError); ok && syntaxErr.Kind == SyntaxInvalidInput {
+		segs := CmdlineField(input).Strings()
+		syntaxErr.Suggestions = s.didYouMean(syntaxErr.Token, schema.expectedHelps(segs[:syntaxErr.Index]))
+	}
+
+	return err
+}
+
+func (t *schemaTop) validate(input string) error {
+
+	fields := CmdlineField(input)
+	if err := fields.Err(); err != nil {
+		return err
+	}
+
+	segs := fields.Strings()
+
+	if len(segs) == 0 {
+		return nil
+	}
+
+	progress := 0
+	var matches []*cmdMatch
+
+	for i := range t.Commands {
+
+		found, p := t.Commands[i].match(segs)
+		matches = append(matches, found...)
+
+		if p > progress {
+			progress = p
+		}
+	}
+
+	if len(matches) != 0 {
+
+		//the line is taken the way it is expanded, which must be a single one
+		matches = bestMatches(matches, len(segs))
+
+		if i, candidates := ambiguousToken(matches, len(segs)); len(candidates) != 0 {
+			return &SyntaxError{Kind: SyntaxAmbiguous, Index: i, Token: segs[i], Pos: fields.Offsets()[i], Candidates: candidates}
+		}
+
+		for _, m := range matches {
+			if m.complete() {
+				return nil
+			}
+		}
+
+		return &SyntaxError{Kind: SyntaxIncomplete, Index: len(segs), Pos: -1, Expected: t.expected(segs)}
+	}
+
+	return &SyntaxError{
+		Kind:     SyntaxInvalidInput,
+		Index:    progress,
+		Token:    segs[progress],
+		Pos:      fields.Offsets()[progress],
+		Expected: t.expected(segs[:progress]),
+	}
+}
+
+//the way the command line is taken to be executed, nil if it can not be or it is ambiguous
+func (t *schemaTop) lineMatch(segs []string) *cmdMatch {
+
+	if len(segs) == 0 {
+		return nil
+	}
+
+	var matches []*cmdMatch
+
+	for i := range t.Commands {
+		found, _ := t.Commands[i].match(segs)
+		matches = append(matches, found...)
+	}
+
+	matches = bestMatches(matches, len(segs))
+
+	if _, candidates := ambiguousToken(matches, len(segs)); len(candidates) != 0 {
+		return nil
+	}
+
+	for _, m := range matches {
+		if m.complete() {
+			return m
+		}
+	}
+
+	return nil
+}
+
+//Executable tells if a command line can be executed as it is
+func (s *Completer) Executable(input string) bool {
+	return s.current().executable(input)
+}
+
+func (t *schemaTop) executable(input string) bool {
+	return len(CmdlineField(input).Strings()) != 0 && t.validate(input) == nil
+}
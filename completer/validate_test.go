@@ -0,0 +1,160 @@
+package completer
+
+import (
+	"reflect"
+	"testing"
+)
+
+//the schema most of the tests of the package run against
+const testSchema = `{
+  "commands": [
+    {"name": "show interface", "prefix": "show interface", "comment": "Show interfaces",
+     "param": [
+       {"name": "name: interface name", "type": "plain"},
+       {"name": "detail", "type": "selection", "range": ["brief: brief info", "detail: detailed info"], "condition": ["- eq name"], "optional": true}
+     ]},
+    {"name": "show vlan", "prefix": "show vlan",
+     "param": [
+       {"name": "id: vlan id", "type": "integer", "range": "1-4094"}
+     ]},
+    {"name": "shutdown", "prefix": "shutdown"},
+    {"name": "set", "prefix": "set",
+     "param": [
+       {"name": "key", "type": "selection", "range": ["speed", "status", "description"]},
+       {"name": "value", "type": "plain"},
+       {"name": "force", "type": "selection", "range": ["force"], "optional": true},
+       {"name": "tag", "type": "plain", "condition": ["*"], "optional": true, "uniq": true}
+     ]}
+  ]
+}`
+
+func newTestCompleter(t *testing.T, schema string) (c *Completer) {
+	t.Helper()
+
+	c = new(Completer)
+	if err := c.SetupBytes([]byte(schema), FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	return
+}
+
+func TestValidate(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	tests := []struct {
+		input      string
+		kind       SyntaxErrorKind //of the error, if not ok
+		ok         bool
+		index      int
+		pos        int
+		candidates []string
+	}{
+		{input: "", ok: true},
+		{input: "show interface eth0", ok: true},
+		{input: "sh int eth0 br", ok: true},
+		{input: "SHOW Interface eth0 DETAIL", ok: true},
+		{input: "show vlan 100", ok: true},
+		{input: "shu", ok: true},
+		{input: "set speed 100 force tag1", ok: true},
+		{input: "set speed 100 tag1", ok: true},
+		{input: "show", kind: SyntaxIncomplete, index: 1, pos: -1},
+		{input: "show vlan", kind: SyntaxIncomplete, index: 2, pos: -1},
+		{input: "show vlan 5000", kind: SyntaxInvalidInput, index: 2, pos: 10},
+		{input: "show interface eth0 bogus", kind: SyntaxInvalidInput, index: 3, pos: 20},
+		{input: "nothing", kind: SyntaxInvalidInput, index: 0, pos: 0},
+		{input: `show interface "eth0`, kind: SyntaxUnclosedQuote, index: 2, pos: 15},
+		//the unique param can not be given twice
+		{input: "set speed 100 tag1 tag2", kind: SyntaxInvalidInput, index: 4, pos: 19},
+		//abbreviations of more than one command
+		{input: "s", kind: SyntaxAmbiguous, index: 0, pos: 0, candidates: []string{"set", "show", "shutdown"}},
+		{input: "sh", kind: SyntaxAmbiguous, index: 0, pos: 0, candidates: []string{"show", "shutdown"}},
+		{input: "  sh int eth0", ok: true},
+		{input: "set s 1", kind: SyntaxAmbiguous, index: 1, pos: 4, candidates: []string{"speed", "status"}},
+	}
+
+	for _, test := range tests {
+
+		err := c.Validate(test.input)
+
+		if test.ok {
+			if err != nil {
+				t.Errorf("%q: %v", test.input, err)
+			}
+			if executable := c.Executable(test.input); executable != (test.input != "") {
+				t.Errorf("%q: executable %v", test.input, executable)
+			}
+			continue
+		}
+
+		syntaxErr, ok := err.(*SyntaxError)
+		if !ok {
+			t.Errorf("%q: %v, expected a *SyntaxError", test.input, err)
+			continue
+		}
+
+		if syntaxErr.Kind != test.kind || syntaxErr.Index != test.index || syntaxErr.Pos != test.pos {
+			t.Errorf("%q: %s at %d, offset %d, expected %s at %d, offset %d", test.input,
+				syntaxErr.Kind, syntaxErr.Index, syntaxErr.Pos, test.kind, test.index, test.pos)
+		}
+
+		if candidates := stringsUniq(syntaxErr.Candidates); !reflect.DeepEqual(candidates, stringsUniq(test.candidates)) {
+			t.Errorf("%q: candidates %q, expected %q", test.input, syntaxErr.Candidates, test.candidates)
+		}
+
+		if c.Executable(test.input) {
+			t.Errorf("%q: executable", test.input)
+		}
+	}
+}
+
+func TestValidateAgreesWithExpand(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	for _, input := range []string{"s", "sh", "shu", "sh int eth0", "set s 1", "set sp 1", "show vlan 1"} {
+
+		_, expandErr := c.Expand(input)
+		validateErr := c.Validate(input)
+
+		if _, ambiguous := expandErr.(*AmbiguousError); ambiguous {
+			if syntaxErr, ok := validateErr.(*SyntaxError); !ok || syntaxErr.Kind != SyntaxAmbiguous {
+				t.Errorf("%q: expanded with %v, validated with %v", input, expandErr, validateErr)
+			}
+		} else if validateErr != nil && validateErr.(*SyntaxError).Kind == SyntaxAmbiguous {
+			t.Errorf("%q: validated as ambiguous, expanded", input)
+		}
+	}
+}
+
+func TestExpand(t *testing.T) {
+
+	c := newTestCompleter(t, testSchema)
+
+	tests := []struct {
+		input    string
+		expanded string
+		err      bool
+	}{
+		{"sh int eth0 br", "show interface eth0 brief", false},
+		{"shu", "shutdown", false},
+		{"set sp 100 f", "set speed 100 force", false},
+		{"SHOW int eth0", "show interface eth0", false},
+		//the fields not abbreviated are kept as they are
+		{`sh  int name="a b"  br`, `show  interface name="a b"  brief`, false},
+		{`set sp 'x y' tag\ 1`, `set speed 'x y' tag\ 1`, false},
+		{`  show interface "eth0"`, `  show interface "eth0"`, false},
+		{"unknown line", "unknown line", false},
+		{"sh", "sh", true},
+	}
+
+	for _, test := range tests {
+
+		expanded, err := c.Expand(test.input)
+
+		if (err != nil) != test.err || expanded != test.expanded {
+			t.Errorf("%q: expanded to %q, %v, expected %q", test.input, expanded, err, test.expanded)
+		}
+	}
+}
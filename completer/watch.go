@@ -0,0 +1,71 @@
+package completer
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+)
+
+//what the schema files look like now, it changes when a file is modified, added or removed
+func (s *Completer) filesStamp() string {
+
+	s.lock.RLock()
+	files, watched := s.files, s.watched
+	s.lock.RUnlock()
+
+	var stamp strings.Builder
+
+	for _, path := range watched {
+		if info, err := files.stat(path); err != nil {
+			fmt.Fprintf(&stamp, "%s -\n", path)
+		} else {
+			fmt.Fprintf(&stamp, "%s %d %d\n", path, info.Size(), info.ModTime().UnixNano())
+		}
+	}
+
+	return stamp.String()
+}
+
+//Watch checks the schema files every interval, and reloads the schema when they change.
+//reloaded, if not nil, is called with the result of each reload. The sessions go on with the schema
+//in use until a reload succeeds. Call stop to stop watching.
+func (s *Completer) Watch(interval time.Duration, reloaded func(err error)) (stop func()) {
+
+	done := make(chan struct{})
+	stamp := s.filesStamp()
+
+	go func() {
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			now := s.filesStamp()
+			if now == stamp {
+				continue
+			}
+
+			err := s.Reload()
+
+			//the files watched may have changed with the includes
+			stamp = s.filesStamp()
+
+			if reloaded != nil {
+				reloaded(err)
+			}
+		}
+	}()
+
+	var once sync.Once
+
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
@@ -0,0 +1,91 @@
+package completer
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+//the result of the next reload of the watched schema
+func nextReload(t *testing.T, reloads <-chan error) error {
+	t.Helper()
+
+	select {
+	case err := <-reloads:
+		return err
+	case <-time.After(5 * time.Second):
+		t.Fatal("not reloaded")
+		return nil
+	}
+}
+
+func TestWatch(t *testing.T) {
+
+	dir := writeSchemaFiles(t, map[string]string{"a.json": `{"commands": [{"name": "a", "prefix": "a"}]}`})
+	path := filepath.Join(dir, "a.json")
+
+	c := new(Completer)
+	if err := c.Setup(path); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := make(chan error, 10)
+	stop := c.Watch(10*time.Millisecond, func(err error) { reloads <- err })
+	defer stop()
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [{"name": "bb", "prefix": "bb"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := nextReload(t, reloads); err != nil {
+		t.Fatal(err)
+	}
+
+	if c.Validate("bb") != nil || c.Validate("a") == nil {
+		t.Errorf("the schema rewritten is not in use")
+	}
+
+	//not reloaded again while the files stay the same
+	select {
+	case err := <-reloads:
+		t.Errorf("reloaded again with %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+//a broken file is reported, the schema in use is kept until the file is fixed
+func TestWatchError(t *testing.T) {
+
+	dir := writeSchemaFiles(t, map[string]string{"a.json": `{"commands": [{"name": "a", "prefix": "a"}]}`})
+	path := filepath.Join(dir, "a.json")
+
+	c := new(Completer)
+	if err := c.Setup(path); err != nil {
+		t.Fatal(err)
+	}
+
+	reloads := make(chan error, 10)
+	stop := c.Watch(10*time.Millisecond, func(err error) { reloads <- err })
+	defer stop()
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, ok := nextReload(t, reloads).(*SchemaError); !ok {
+		t.Fatal("the broken schema is not reported")
+	}
+
+	if err := c.Validate("a"); err != nil {
+		t.Errorf("the schema in use is not kept: %v", err)
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [{"name": "bb", "prefix": "bb"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := nextReload(t, reloads); err != nil || c.Validate("bb") != nil {
+		t.Errorf("reloaded with %v", err)
+	}
+}
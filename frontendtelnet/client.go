@@ -1,29 +1,47 @@
 package frontendtelnet
 
 import (
-	"bytes"
 	"errors"
 	"regexp"
 	"strings"
+	"unicode/utf8"
 
+	"github.com/ershixiongTQL/cli-ui/completer"
 	"github.com/ershixiongTQL/cli-ui/frontendtelnet/protocol"
 	"github.com/ershixiongTQL/cli-ui/history"
+	"github.com/ershixiongTQL/cli-ui/interfaces"
 )
 
 type client struct {
-	server       *Server
-	conn         *protocol.Conn
-	inLineBuffer *bytes.Buffer
-	lineCursor   int
-	history      *history.HRing
+	server     *Server
+	conn       *protocol.Conn
+	line       []rune
+	lineCursor int //in runes
+
+	promptWidth int
+	history     *history.HRing
+
+	pending string //the lines given so far of an unfinished multi-line command
+
+	killRing   []string
+	yankStart  int
+	lastAction EditAction
+
+	confirm func(yes bool) //takes the answer to a question asked, e.g. whether to list many completions
+
+	suggestion      string //the rest of the line suggested
+	suggestionShown bool
+
+	highlight bool //color the line, see Config.Highlight
 }
 
 func newClient(s *Server, conn *protocol.Conn) (c *client) {
 	c = new(client)
 	c.server = s
 	c.conn = conn
-	c.inLineBuffer = bytes.NewBuffer(make([]byte, 0, 2048))
+	c.line = make([]rune, 0, 2048)
 	c.history = history.NewHRing(1024)
+	c.highlight = s.config.Highlight
 	return
 }
 
@@ -37,6 +55,8 @@ func (c *client) negotiate() {
 	c.conn.SetUnixWriteMode(true)
 	c.conn.Will(protocol.OptSuppressGoAhead)
 	c.conn.Will(protocol.OptEcho)
+	c.conn.RequestWindowSize()
+	c.conn.RequestTerminalType()
 }
 
 func (c *client) print(text string) {
@@ -44,21 +64,21 @@ func (c *client) print(text string) {
 }
 
 func (c *client) printPrompt() {
-	c.conn.Write([]byte(c.server.config.GetPrompt()))
-	c.conn.Write([]byte("# "))
-}
 
-func (c *client) close() {
-	c.conn.Close()
-}
+	if c.pending != "" {
+		if getPrompt := c.server.config.GetContinuationPrompt; getPrompt != nil {
+			c.printPromptText(getPrompt())
+		} else {
+			c.printPromptText("> ")
+		}
+		return
+	}
 
-func (c *client) clientCursorBack(num int) {
-	c.print(strings.Repeat("\b", num))
+	c.printPromptText(c.server.config.GetPrompt() + "# ")
 }
 
-//unsafe
-func (c *client) clientCursorForward(num int) {
-	c.conn.Write(c.inLineBuffer.Bytes()[c.lineCursor : c.lineCursor+num])
+func (c *client) close() {
+	c.conn.Close()
 }
 
 func (c *client) cursorMoveCheck(move int) (moved int) {
@@ -76,12 +96,7 @@ func (c *client) cursorMove(move int) (ok bool) {
 		return false
 	}
 
-	if move < 0 {
-		c.clientCursorBack(-move)
-	} else if move > 0 {
-		c.clientCursorForward(move)
-	}
-
+	c.moveCursor(c.lineCursor, c.lineCursor+move)
 	c.lineCursor += move
 	return true
 }
@@ -91,56 +106,59 @@ func (c *client) cursorHome() {
 }
 
 func (c *client) cursorEnd() {
-	c.cursorMove(c.inLineBuffer.Len() - c.lineCursor)
+	c.cursorMove(len(c.line) - c.lineCursor)
 }
 
 func (c *client) inLineBs() {
-
 	if c.cursorMoveCheck(-1) == -1 {
-
-		if !c.isCursorAtTheEnd() {
-			c.lineCursor -= 1
-			endPos := c.lineCursor
-			goback := c.inLineBuffer.Len() - endPos - 1
-
-			newLine := append(c.inLineBuffer.Bytes()[:endPos], c.inLineBuffer.Bytes()[endPos+1:]...)
-			c.inLineBuffer.Reset()
-			c.inLineBuffer.Write(newLine)
-			c.print("\b" + string(newLine[endPos:]) + " \b")
-			c.clientCursorBack(goback)
-		} else {
-			c.print("\b \b") //wipe display
-			line := c.inLineBuffer.Bytes()
-			lineLen := c.inLineBuffer.Len()
-			c.inLineBuffer.Reset()
-			c.inLineBuffer.Write(line[:lineLen-1])
-			c.lineCursor -= 1
-		}
-
+		c.lineReplace(c.lineCursor-1, c.lineCursor, nil)
 	}
 }
 
 func (c *client) inlineDel() {
 	if c.cursorMoveCheck(1) == 1 {
-		if c.lineCursor == c.inLineBuffer.Len()-1 {
-			newLine := c.inLineBuffer.Bytes()[:c.lineCursor]
-			c.inLineBuffer.Reset()
-			c.inLineBuffer.Write(newLine)
-			c.print(" \b")
-		} else {
-			newLine := append(c.inLineBuffer.Bytes()[:c.lineCursor], c.inLineBuffer.Bytes()[c.lineCursor+1:]...)
-			c.inLineBuffer.Reset()
-			c.inLineBuffer.Write(newLine)
-			c.print(string(newLine[c.lineCursor:]) + " ")
-			c.clientCursorBack(len(newLine[c.lineCursor:]) + 1)
-		}
+		c.lineReplace(c.lineCursor, c.lineCursor+1, nil)
+	}
+}
+
+//check if a command needs more lines: an unfinished heredoc, a trailing backslash or an unclosed quote.
+//pending is how the command continues on the next line
+func continuation(command string) (pending string, more bool) {
+
+	if _, _, isHeredoc, complete := completer.SplitHeredoc(command); isHeredoc {
+		return command + "\n", !complete
+	}
+
+	trimmed := strings.TrimRight(command, "\\")
+	if (len(command)-len(trimmed))%2 == 1 {
+		return command[:len(command)-1], true
 	}
+
+	if fields := completer.CmdlineField(command); fields.Unclosed() {
+		return command + "\n", true
+	}
+
+	return "", false
+}
+
+//is the line a part of a heredoc body, which is taken literally
+func (c *client) inHeredoc() bool {
+	_, _, isHeredoc, _ := completer.SplitHeredoc(c.pending)
+	return isHeredoc
 }
 
 func (c *client) exec() error {
-	line := c.getLine()
+	singleLine := c.pending == ""
+	line := c.pending + c.getLine()
 	c.inLineClear()
 
+	if pending, more := continuation(line); more {
+		c.pending = pending
+		return nil
+	}
+
+	c.pending = ""
+
 	re := regexp.MustCompile(`^\s*(exit|quit)\s*$`)
 
 	if re.FindString(line) != "" {
@@ -148,7 +166,12 @@ func (c *client) exec() error {
 	}
 
 	if handler := c.server.config.Backend.CommandHandler; handler != nil {
-		handler(line, c)
+		if err := handler(line, c); err != nil {
+			if perr, ok := err.(interfaces.PositionedError); ok {
+				c.printErrorMarker(line, perr.Offset(), singleLine)
+				c.print(perr.Error())
+			}
+		}
 	}
 	c.WriteString("\n")
 	c.history.Append(line)
@@ -156,104 +179,102 @@ func (c *client) exec() error {
 	return nil
 }
 
+//print a '^' under the position of the line just entered
+func (c *client) printErrorMarker(line string, offset int, singleLine bool) {
+
+	if offset < 0 || offset > len(line) || !singleLine {
+		return
+	}
+
+	col := c.promptWidth + runesWidth([]rune(line[:offset]))
+	if cols := c.cols(); cols > 0 {
+		col %= cols
+	}
+
+	c.print(strings.Repeat(" ", col) + "^\n")
+}
+
 func (c *client) getCompletions() {
 
-	if !c.isCursorAtTheEnd() {
+	if !c.isCursorAtTheEnd() || c.inHeredoc() {
 		return
 	}
 
-	var completions []string
+	var completions []interfaces.Completion
+	var replace int
+	input := c.pending + c.getLine()
 
 	if completer := c.server.config.Backend.Completer; completer != nil {
-		completions = completer(c.getLine())
+		completions, replace = completer(input)
+	}
+
+	//the runes of the line replaced
+	replaced := utf8.RuneCountInString(input[len(input)-replace:])
+	if replaced > c.lineCursor {
+		completions = nil
+	}
+
+	executable := false
+	if checker, ok := c.server.config.Backend.(interfaces.ExecutableChecker); ok {
+		executable = checker.Executable(input)
 	}
 
 	if len(completions) == 1 {
 		//single option, simply print
-		c.lineAppend([]byte(completions[0]))
+		c.lineReplace(c.lineCursor-replaced, c.lineCursor, []rune(completions[0].Text))
 	} else if len(completions) > 1 {
 
-		c.print("\n")
-		c.print(strings.Join(completions, " "))
-		c.print("\n")
-		c.printPrompt()
-		c.print(c.getLine())
+		if executable {
+			completions = append(completions, interfaces.Completion{Text: "<cr>", Description: "Execute the command"})
+		}
+
+		c.listCompletions(completions)
+	} else if executable {
+		//nothing more to give, tell that the line can be executed
+		c.print("\n<cr>\n")
+		c.redraw()
 	} else {
-		//no completion
-		//TODO: <ENTER> hint
-		return
+		c.print("\a")
 	}
 }
 
 func (c *client) getHelp() {
 
 	if helper := c.server.config.Backend.Helps; helper != nil {
-		help := helper(c.getLine())
+		help := helper(c.pending + c.getLine())
 
 		if help != "" {
+			c.moveCursor(c.lineCursor, len(c.line))
 			c.print("\n" + help + "\n")
-			c.printPrompt()
-			c.print(c.getLine())
+			c.redraw()
 		}
 	}
 }
 
 func (c *client) isCursorAtTheEnd() bool {
-	return c.lineCursor >= c.inLineBuffer.Len()
+	return c.lineCursor >= len(c.line)
 }
 
-func (c *client) lineAppend(chars []byte) {
-
-	if c.isCursorAtTheEnd() {
-		//Append
-
-		c.inLineBuffer.Write(chars)
-		c.lineCursor += len(chars)
-		c.print(string(chars))
-
-	} else {
-		//Insert
-
-		startPos := c.lineCursor
-
-		seg2 := append([]byte{}, c.inLineBuffer.Bytes()[startPos:]...)
-		newLine := append(c.inLineBuffer.Bytes()[:startPos], chars...)
-		newLine = append(newLine, seg2...)
-
-		c.inLineBuffer.Reset()
-		c.inLineBuffer.Write(newLine)
-
-		c.print(string(chars))
-		c.print(string(newLine[startPos+len(chars):]))
-		c.clientCursorBack(len(newLine[startPos+len(chars):]))
-		c.lineCursor += len(chars)
-	}
+func (c *client) lineAppend(chars []rune) {
+	c.lineReplace(c.lineCursor, c.lineCursor, chars)
 }
 
 func (c *client) allLineClear() {
-	for {
-		if c.lineCursor > 0 {
-			c.print("\b \b") //wipe display
-			c.lineCursor -= 1
-		} else {
-			c.inLineBuffer.Reset()
-			break
-		}
-	}
+	c.lineReplace(0, c.getLineLen(), nil)
 }
 
 func (c *client) inLineClear() {
-	c.inLineBuffer.Reset()
+	c.line = c.line[:0]
 	c.lineCursor = 0
 }
 
 func (c *client) getLine() string {
-	return c.inLineBuffer.String()
+	return string(c.line)
 }
 
+//length of the line in runes
 func (c *client) getLineLen() int {
-	len := c.inLineBuffer.Len()
-	return len
+	return len(c.line)
 }
 
 func (c *client) historyCheckout(previous bool) {
@@ -270,18 +291,10 @@ func (c *client) historyCheckout(previous bool) {
 		ok = c.history.PosForward()
 	}
 
-	currLineLen := c.getLineLen()
-	if currLineLen != 0 {
-		for i := 0; i < currLineLen; i++ {
-			c.conn.Write([]byte("\b \b"))
-		}
-	}
-
-	c.inLineClear()
+	c.allLineClear()
 
 	if ok {
-		content := []byte(c.history.Read())
-		c.lineAppend(content)
+		c.lineAppend([]rune(c.history.Read()))
 	}
 
 }
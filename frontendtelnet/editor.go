@@ -0,0 +1,223 @@
+package frontendtelnet
+
+import (
+	"errors"
+	"unicode"
+)
+
+const killRingSize = 16
+
+//run an editing action, a non-nil error means the session should be closed
+func (c *client) do(action EditAction) (err error) {
+
+	lastAction := c.lastAction
+	c.lastAction = action
+
+	switch action {
+	case ActionAcceptLine:
+		c.moveCursor(c.lineCursor, len(c.line))
+		c.print("\n")
+		if c.getLineLen() > 0 || c.pending != "" {
+			if err = c.exec(); err != nil {
+				return
+			}
+		}
+		c.printPrompt()
+	case ActionComplete:
+		c.getCompletions()
+	case ActionHelp:
+		c.getHelp()
+	case ActionBackwardDeleteChar:
+		c.inLineBs()
+	case ActionDeleteChar:
+		c.inlineDel()
+	case ActionBackwardChar:
+		c.cursorMove(-1)
+	case ActionForwardChar:
+		if !c.acceptSuggestion() {
+			c.cursorMove(1)
+		}
+	case ActionBackwardWord:
+		c.cursorMove(c.wordStart() - c.lineCursor)
+	case ActionForwardWord:
+		c.cursorMove(c.wordEnd() - c.lineCursor)
+	case ActionBeginningOfLine:
+		c.cursorHome()
+	case ActionEndOfLine:
+		c.cursorEnd()
+	case ActionKillLine:
+		c.kill(c.lineCursor, c.getLineLen(), lastAction)
+	case ActionKillLineBackward:
+		c.kill(0, c.lineCursor, lastAction)
+	case ActionKillWord:
+		c.kill(c.lineCursor, c.wordEnd(), lastAction)
+	case ActionKillWordBackward:
+		c.kill(c.spaceWordStart(), c.lineCursor, lastAction)
+	case ActionYank:
+		c.yank()
+	case ActionYankPop:
+		if lastAction != ActionYank && lastAction != ActionYankPop {
+			c.lastAction = lastAction
+			return
+		}
+		c.yankPop()
+	case ActionTransposeChars:
+		c.transpose()
+	case ActionClearScreen:
+		c.clearScreen()
+	case ActionPreviousHistory:
+		c.historyCheckout(true)
+	case ActionNextHistory:
+		c.historyCheckout(false)
+	case ActionToggleHighlight:
+		c.toggleHighlight()
+	case ActionCloseSession:
+		return errors.New("closed by user")
+	}
+
+	return
+}
+
+//insert a typed character, it breaks a sequence of kills or yanks
+func (c *client) selfInsert(chars []rune) {
+	c.lastAction = ActionNone
+	c.lineAppend(chars)
+}
+
+//replace line[start:end] with the runes and redisplay, the cursor is left behind the inserted runes
+func (c *client) lineReplace(start, end int, with []rune) {
+
+	c.moveCursorToWrite(start)
+
+	oldWidth := runesWidth(c.line[start:])
+
+	newLine := append(append([]rune{}, c.line[:start]...), with...)
+	c.line = append(newLine, c.line[end:]...)
+
+	c.writeTail(start, oldWidth)
+
+	c.lineCursor = start + len(with)
+	c.moveCursor(len(c.line), c.lineCursor)
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+//start of the word before the cursor, words are made of letters and digits
+func (c *client) wordStart() (pos int) {
+	pos = c.lineCursor
+	for pos > 0 && !isWordRune(c.line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && isWordRune(c.line[pos-1]) {
+		pos--
+	}
+	return
+}
+
+//end of the word after the cursor, words are made of letters and digits
+func (c *client) wordEnd() (pos int) {
+	pos = c.lineCursor
+	for pos < len(c.line) && !isWordRune(c.line[pos]) {
+		pos++
+	}
+	for pos < len(c.line) && isWordRune(c.line[pos]) {
+		pos++
+	}
+	return
+}
+
+//start of the whitespace delimited word before the cursor
+func (c *client) spaceWordStart() (pos int) {
+	pos = c.lineCursor
+	for pos > 0 && unicode.IsSpace(c.line[pos-1]) {
+		pos--
+	}
+	for pos > 0 && !unicode.IsSpace(c.line[pos-1]) {
+		pos--
+	}
+	return
+}
+
+func isKillAction(action EditAction) bool {
+	switch action {
+	case ActionKillLine, ActionKillLineBackward, ActionKillWord, ActionKillWordBackward:
+		return true
+	}
+	return false
+}
+
+//cut line[start:end] into the kill ring, consecutive kills are merged into one entry
+func (c *client) kill(start, end int, lastAction EditAction) {
+
+	if start >= end {
+		return
+	}
+
+	killed := string(c.line[start:end])
+
+	if isKillAction(lastAction) && len(c.killRing) != 0 {
+		top := &c.killRing[len(c.killRing)-1]
+		if start < c.lineCursor {
+			*top = killed + *top
+		} else {
+			*top += killed
+		}
+	} else {
+		c.killRing = append(c.killRing, killed)
+		if len(c.killRing) > killRingSize {
+			c.killRing = c.killRing[1:]
+		}
+	}
+
+	c.lineReplace(start, end, nil)
+}
+
+func (c *client) yank() {
+
+	if len(c.killRing) == 0 {
+		return
+	}
+
+	text := []rune(c.killRing[len(c.killRing)-1])
+	c.yankStart = c.lineCursor
+	c.lineReplace(c.lineCursor, c.lineCursor, text)
+}
+
+//replace the text just yanked with the previous entry of the kill ring
+func (c *client) yankPop() {
+
+	if len(c.killRing) < 2 {
+		return
+	}
+
+	//rotate, the top entry goes to the bottom
+	top := c.killRing[len(c.killRing)-1]
+	c.killRing = append([]string{top}, c.killRing[:len(c.killRing)-1]...)
+
+	text := []rune(c.killRing[len(c.killRing)-1])
+	c.lineReplace(c.yankStart, c.lineCursor, text)
+}
+
+//swap the characters around the cursor, or the last two ones at the end of line
+func (c *client) transpose() {
+
+	pos := c.lineCursor
+
+	if pos == 0 || len(c.line) < 2 {
+		return
+	}
+
+	if pos == len(c.line) {
+		pos--
+	}
+
+	c.lineReplace(pos-1, pos+1, []rune{c.line[pos], c.line[pos-1]})
+}
+
+//clear the screen and redraw the prompt and the line
+func (c *client) clearScreen() {
+	c.print("\x1b[H\x1b[2J")
+	c.redraw()
+}
@@ -0,0 +1,102 @@
+package frontendtelnet
+
+import (
+	"strings"
+
+	"github.com/ershixiongTQL/cli-ui/interfaces"
+)
+
+//Highlighting of the input line. The line is painted again with ANSI colors once a key is handled:
+//the keywords bold, the valid values green, the invalid token red.
+//The runes are the same, so are the positions on the screen.
+
+var tokenColors = map[interfaces.TOKEN_CLASS]string{
+	interfaces.TOKEN_CLASS_KEYWORD: "\x1b[1m",
+	interfaces.TOKEN_CLASS_VALUE:   "\x1b[32m",
+	interfaces.TOKEN_CLASS_INVALID: "\x1b[31m",
+}
+
+//is the line highlighted for the client, not if the backend does not classify the tokens
+func (c *client) highlighting() bool {
+
+	if !c.highlight || dumbTerminal(c.conn.TerminalType()) {
+		return false
+	}
+
+	_, ok := c.server.config.Backend.(interfaces.Classifier)
+
+	return ok
+}
+
+//the line with the tokens colored
+func (c *client) highlightedLine() string {
+
+	line := c.getLine()
+
+	classifier, ok := c.server.config.Backend.(interfaces.Classifier)
+	if !ok {
+		return line
+	}
+
+	var buf strings.Builder
+	done := 0
+
+	for _, t := range classifier.Classify(line) {
+
+		color, colored := tokenColors[t.Class]
+		if !colored || t.Start < done || t.End > len(line) {
+			continue
+		}
+
+		buf.WriteString(line[done:t.Start])
+		buf.WriteString(color + line[t.Start:t.End] + "\x1b[0m")
+		done = t.End
+	}
+
+	buf.WriteString(line[done:])
+
+	return buf.String()
+}
+
+//paint the line again, highlighted or not
+func (c *client) paintLine(highlighted bool) {
+
+	if len(c.line) == 0 || c.pending != "" {
+		return
+	}
+
+	c.moveCursorToWrite(0)
+
+	if highlighted {
+		c.print(c.highlightedLine())
+	} else {
+		c.print(c.getLine())
+	}
+
+	if _, col := c.writePos(len(c.line)); col == 0 && c.cols() > 0 {
+		//as writeTail does for a full last row
+		c.print("\n")
+	}
+
+	c.moveCursor(len(c.line), c.lineCursor)
+}
+
+//turn the highlighting of the session on or off
+func (c *client) toggleHighlight() {
+	c.highlight = !c.highlight
+	c.paintLine(c.highlighting())
+}
+
+//highlight the line and show the suggestion, after a key is handled
+func (c *client) decorate() {
+
+	if c.confirm != nil {
+		return
+	}
+
+	if c.highlighting() {
+		c.paintLine(true)
+	}
+
+	c.suggest()
+}
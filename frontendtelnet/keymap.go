@@ -0,0 +1,187 @@
+package frontendtelnet
+
+import "strings"
+
+//EditAction is a function of the line editor which can be bound to a key
+type EditAction int
+
+const (
+	ActionNone EditAction = iota
+	ActionAcceptLine
+	ActionComplete
+	ActionHelp
+	ActionBackwardDeleteChar
+	ActionDeleteChar
+	ActionBackwardChar
+	ActionForwardChar
+	ActionBackwardWord
+	ActionForwardWord
+	ActionBeginningOfLine
+	ActionEndOfLine
+	ActionKillLine
+	ActionKillLineBackward
+	ActionKillWord
+	ActionKillWordBackward
+	ActionYank
+	ActionYankPop
+	ActionTransposeChars
+	ActionClearScreen
+	ActionPreviousHistory
+	ActionNextHistory
+	ActionCloseSession
+	ActionToggleHighlight
+)
+
+var actionNames = map[EditAction]string{
+	ActionNone:               "none",
+	ActionAcceptLine:         "accept-line",
+	ActionComplete:           "complete",
+	ActionHelp:               "help",
+	ActionBackwardDeleteChar: "backward-delete-char",
+	ActionDeleteChar:         "delete-char",
+	ActionBackwardChar:       "backward-char",
+	ActionForwardChar:        "forward-char",
+	ActionBackwardWord:       "backward-word",
+	ActionForwardWord:        "forward-word",
+	ActionBeginningOfLine:    "beginning-of-line",
+	ActionEndOfLine:          "end-of-line",
+	ActionKillLine:           "kill-line",
+	ActionKillLineBackward:   "backward-kill-line",
+	ActionKillWord:           "kill-word",
+	ActionKillWordBackward:   "unix-word-rubout",
+	ActionYank:               "yank",
+	ActionYankPop:            "yank-pop",
+	ActionTransposeChars:     "transpose-chars",
+	ActionClearScreen:        "clear-screen",
+	ActionPreviousHistory:    "previous-history",
+	ActionNextHistory:        "next-history",
+	ActionCloseSession:       "close-session",
+	ActionToggleHighlight:    "toggle-highlight",
+}
+
+func (a EditAction) String() string {
+	if name, ok := actionNames[a]; ok {
+		return name
+	}
+	return "???"
+}
+
+//KeyBindings maps the key sequences sent by the terminal to editing actions.
+//Keys without a binding are inserted into the line if they are printable.
+//Bind a key to ActionNone to disable it.
+type KeyBindings map[string]EditAction
+
+//DefaultKeyBindings returns the emacs-style bindings used when Config.KeyBindings is nil.
+//The returned table can be modified and handed to Config.KeyBindings to remap keys.
+func DefaultKeyBindings() KeyBindings {
+	return KeyBindings{
+		string(CR):  ActionAcceptLine,
+		string(TAB): ActionComplete,
+		string(QM):  ActionHelp,
+		string(BS):  ActionBackwardDeleteChar,
+		string(DEL): ActionBackwardDeleteChar,
+		string(ETX): ActionCloseSession,
+		string(EOT): ActionCloseSession,
+		string(SUB): ActionCloseSession,
+
+		string(CTRL_A): ActionBeginningOfLine,
+		string(CTRL_B): ActionBackwardChar,
+		string(CTRL_E): ActionEndOfLine,
+		string(CTRL_F): ActionForwardChar,
+		string(CTRL_K): ActionKillLine,
+		string(CTRL_L): ActionClearScreen,
+		string(CTRL_N): ActionNextHistory,
+		string(CTRL_P): ActionPreviousHistory,
+		string(CTRL_T): ActionTransposeChars,
+		string(CTRL_U): ActionKillLineBackward,
+		string(CTRL_W): ActionKillWordBackward,
+		string(CTRL_Y): ActionYank,
+
+		"\x1bb": ActionBackwardWord,
+		"\x1bf": ActionForwardWord,
+		"\x1bd": ActionKillWord,
+		"\x1by": ActionYankPop,
+		"\x1bh": ActionToggleHighlight,
+
+		"\x1b\x1b": ActionCloseSession,
+
+		"\x1b[A":    ActionPreviousHistory, //up
+		"\x1b[B":    ActionNextHistory,     //down
+		"\x1b[C":    ActionForwardChar,     //right
+		"\x1b[D":    ActionBackwardChar,    //left
+		"\x1bOA":    ActionPreviousHistory,
+		"\x1bOB":    ActionNextHistory,
+		"\x1bOC":    ActionForwardChar,
+		"\x1bOD":    ActionBackwardChar,
+		"\x1b[1;5C": ActionForwardWord,  //ctrl-right
+		"\x1b[1;5D": ActionBackwardWord, //ctrl-left
+		"\x1b[1~":   ActionBeginningOfLine,
+		"\x1b[7~":   ActionBeginningOfLine,
+		"\x1b[H":    ActionBeginningOfLine,
+		"\x1bOH":    ActionBeginningOfLine,
+		"\x1b[4~":   ActionEndOfLine,
+		"\x1b[8~":   ActionEndOfLine,
+		"\x1b[F":    ActionEndOfLine,
+		"\x1bOF":    ActionEndOfLine,
+		"\x1b[3~":   ActionDeleteChar,
+	}
+}
+
+//keyTable is the lookup form of KeyBindings used while reading keys
+type keyTable struct {
+	bindings KeyBindings
+	prefixes map[string]bool //proper prefixes of the bound sequences
+}
+
+func newKeyTable(bindings KeyBindings) (t *keyTable) {
+
+	if bindings == nil {
+		bindings = DefaultKeyBindings()
+	}
+
+	t = &keyTable{bindings: bindings, prefixes: make(map[string]bool)}
+
+	for seq := range bindings {
+		runes := []rune(seq)
+		for i := 1; i < len(runes); i++ {
+			t.prefixes[string(runes[:i])] = true
+		}
+	}
+
+	return
+}
+
+//read a key sequence with readRune and look it up, the longest bound sequence wins.
+//bound is false for keys without a binding, seq holds what has been read then.
+//A control sequence (ESC '[') is read up to its final byte, so an unbound one, e.g. PageUp, is left whole.
+func (t *keyTable) read(readRune func() (rune, error)) (seq string, action EditAction, bound bool, err error) {
+
+	var r rune
+
+	for {
+		if r, err = readRune(); err != nil {
+			return
+		}
+
+		seq += string(r)
+
+		if t.prefixes[seq] || inControlSequence(seq) {
+			continue
+		}
+
+		action, bound = t.bindings[seq]
+		return
+	}
+}
+
+//is seq a control sequence without its final byte yet: ESC '[', then parameter and intermediate bytes
+func inControlSequence(seq string) bool {
+
+	if !strings.HasPrefix(seq, "\x1b[") {
+		return false
+	}
+
+	last := seq[len(seq)-1]
+
+	return len(seq) == 2 || last >= 0x20 && last <= 0x3f
+}
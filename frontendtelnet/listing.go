@@ -0,0 +1,112 @@
+package frontendtelnet
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ershixiongTQL/cli-ui/interfaces"
+)
+
+//width of the listing if the terminal does not tell its own
+const defaultListWidth = 80
+
+//ask before listing more completions than this, if Config.ListQueryItems is 0
+const defaultListQueryItems = 100
+
+//what a completion is listed as
+func completionLabel(comp interfaces.Completion) string {
+	if comp.Display != "" {
+		return comp.Display
+	}
+	return strings.TrimSpace(comp.Text)
+}
+
+//listing of the completions fitting the width, in columns filled top to bottom like ls does.
+//With the descriptions, a completion a line, the descriptions lined up after them.
+func formatCompletions(list []interfaces.Completion, width int, withDesc bool) string {
+
+	if width <= 0 {
+		width = defaultListWidth
+	}
+
+	labels := make([][]rune, len(list))
+	maxw := 0
+	for i, comp := range list {
+		labels[i] = []rune(completionLabel(comp))
+		if w := runesWidth(labels[i]); w > maxw {
+			maxw = w
+		}
+	}
+
+	var buf strings.Builder
+
+	if withDesc {
+		for i, comp := range list {
+			buf.WriteString(string(labels[i]))
+			if comp.Description != "" {
+				buf.WriteString(strings.Repeat(" ", maxw+2-runesWidth(labels[i])))
+				buf.WriteString(comp.Description)
+			}
+			buf.WriteString("\n")
+		}
+		return buf.String()
+	}
+
+	colWidth := maxw + 2
+	cols := width / colWidth
+	if cols < 1 {
+		cols = 1
+	}
+	rows := (len(list) + cols - 1) / cols
+
+	for row := 0; row < rows; row++ {
+		for i := row; i < len(list); i += rows {
+			buf.WriteString(string(labels[i]))
+			//pad all but the last one of the row
+			if i+rows < len(list) {
+				buf.WriteString(strings.Repeat(" ", colWidth-runesWidth(labels[i])))
+			}
+		}
+		buf.WriteString("\n")
+	}
+
+	return buf.String()
+}
+
+//how many completions are listed without asking, 0 if always
+func (c *client) listQueryItems() int {
+	switch n := c.server.config.ListQueryItems; {
+	case n == 0:
+		return defaultListQueryItems
+	case n < 0:
+		return 0
+	default:
+		return n
+	}
+}
+
+//list the completions, asking first if there are many
+func (c *client) listCompletions(list []interfaces.Completion) {
+
+	show := func() {
+		c.print(formatCompletions(list, c.cols(), c.server.config.ListDescriptions))
+		c.redraw()
+	}
+
+	c.print("\n")
+
+	if limit := c.listQueryItems(); limit > 0 && len(list) > limit {
+		c.print(fmt.Sprintf("Display all %d possibilities? (y or n)", len(list)))
+		c.confirm = func(yes bool) {
+			c.print("\n")
+			if yes {
+				show()
+			} else {
+				c.redraw()
+			}
+		}
+		return
+	}
+
+	show()
+}
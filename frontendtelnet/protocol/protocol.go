@@ -43,6 +43,12 @@ const (
 	OptNAOFFD          = 13
 )
 
+// Subnegotiation commands of the terminal type option (RFC 1091)
+const (
+	ttypeIs   = 0
+	ttypeSend = 1
+)
+
 type Conn struct {
 	net.Conn
 	r *bufio.Reader
@@ -52,6 +58,12 @@ type Conn struct {
 	cliSuppressGoAhead bool
 	cliEcho            bool
 	cliLineMode        bool
+	cliNAWS            bool
+	cliTerminalType    bool
+
+	width    int
+	height   int
+	termType string
 }
 
 func NewConn(conn net.Conn) (*Conn, error) {
@@ -127,18 +139,43 @@ func (c *Conn) deny(cmd, opt byte) (err error) {
 	return
 }
 
-func (c *Conn) skipSubneg() error {
+// readSubneg reads a subnegotiation up to IAC SE, returns its option and data
+func (c *Conn) readSubneg() (opt byte, data []byte, err error) {
+	if opt, err = c.r.ReadByte(); err != nil {
+		return
+	}
 	for {
-		if b, err := c.r.ReadByte(); err != nil {
-			return err
+		var b byte
+		if b, err = c.r.ReadByte(); err != nil {
+			return
 		} else if b == cmdIAC {
 			if b, err = c.r.ReadByte(); err != nil {
-				return err
+				return
 			} else if b == cmdSE {
-				return nil
+				return
 			}
 		}
+		data = append(data, b)
+	}
+}
+
+func (c *Conn) subneg() error {
+	opt, data, err := c.readSubneg()
+	if err != nil {
+		return err
+	}
+	switch opt {
+	case OptNAWS:
+		if len(data) == 4 {
+			c.width = int(data[0])<<8 | int(data[1])
+			c.height = int(data[2])<<8 | int(data[3])
+		}
+	case OptTerminalType:
+		if len(data) != 0 && data[0] == ttypeIs {
+			c.termType = string(data[1:])
+		}
 	}
+	return nil
 }
 
 func (c *Conn) cmd(cmd byte) error {
@@ -149,7 +186,7 @@ func (c *Conn) cmd(cmd byte) error {
 	case cmdDo, cmdDont, cmdWill, cmdWont:
 		// Process cmd after this switch.
 	case cmdSB:
-		return c.skipSubneg()
+		return c.subneg()
 	case cmdEl:
 		c.Conn.Write([]byte{cmdEl})
 		return nil
@@ -214,15 +251,42 @@ func (c *Conn) cmd(cmd byte) error {
 			}
 		}
 	case OptNAWS:
-		if cmd != cmdDo {
+		// The client tells us its window size, we never send ours
+		switch cmd {
+		case cmdWill:
+			if !c.cliNAWS {
+				c.cliNAWS = true
+				err = c.do(o)
+			}
+		case cmdWont:
+			c.width, c.height = 0, 0
+			if c.cliNAWS {
+				c.cliNAWS = false
+				err = c.dont(o)
+			}
+		default:
 			err = c.deny(cmd, o)
-			break
 		}
-		if err = c.Will(o); err != nil {
-			break
+	case OptTerminalType:
+		// The client tells us its terminal type when asked, we never send ours
+		switch cmd {
+		case cmdWill:
+			if !c.cliTerminalType {
+				c.cliTerminalType = true
+				err = c.do(o)
+			}
+			if err == nil {
+				err = c.sub(o, ttypeSend)
+			}
+		case cmdWont:
+			c.termType = ""
+			if c.cliTerminalType {
+				c.cliTerminalType = false
+				err = c.dont(o)
+			}
+		default:
+			err = c.deny(cmd, o)
 		}
-		// Reply with max window size: 65535x65535
-		err = c.sub(o, 255, 255, 255, 255)
 	case OptLineMode:
 		switch cmd {
 		case cmdDo:
@@ -273,6 +337,31 @@ func (c *Conn) tryReadByte() (b byte, retry bool, err error) {
 	return
 }
 
+// RequestWindowSize asks the client to report its window size and the
+// following changes of it (NAWS, RFC 1073).
+func (c *Conn) RequestWindowSize() error {
+	c.cliNAWS = true
+	return c.do(OptNAWS)
+}
+
+// WindowSize returns the last window size reported by the client, zeros
+// if the client has not reported it.
+func (c *Conn) WindowSize() (width, height int) {
+	return c.width, c.height
+}
+
+// RequestTerminalType asks the client to tell its terminal type (RFC 1091).
+func (c *Conn) RequestTerminalType() error {
+	c.cliTerminalType = true
+	return c.do(OptTerminalType)
+}
+
+// TerminalType returns the terminal type told by the client, e.g. "XTERM",
+// empty if the client has not told it.
+func (c *Conn) TerminalType() string {
+	return c.termType
+}
+
 func (c *Conn) ClearScreen() {
 	c.Write([]byte{12})
 }
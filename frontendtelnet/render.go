@@ -0,0 +1,164 @@
+package frontendtelnet
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//Rendering of the input line.
+//With the window width reported by the client (NAWS), lines longer than the
+//terminal are wrapped and the cursor is moved with ANSI sequences.
+//Without it, the line is assumed to fit on one row and the cursor is moved
+//by backspaces and rewriting, which any terminal understands.
+
+var ansiSequence = regexp.MustCompile(`\x1b\[[0-9;?]*[A-Za-z]`)
+
+//terminal width, 0 if unknown
+func (c *client) cols() int {
+	width, _ := c.conn.WindowSize()
+	return width
+}
+
+//print the prompt and remember how many columns it takes
+func (c *client) printPromptText(prompt string) {
+	c.print(prompt)
+	lines := strings.Split(ansiSequence.ReplaceAllString(prompt, ""), "\n")
+	c.promptWidth = runesWidth([]rune(lines[len(lines)-1]))
+}
+
+//screen position where the rune at index pos of the line is written, the row
+//is counted from the row the prompt starts at. Wide runes which do not fit on
+//the rest of a row are wrapped to the next row as terminals do.
+func (c *client) writePos(pos int) (row, col int) {
+
+	cols := c.cols()
+	col = c.promptWidth
+
+	if cols <= 0 {
+		return 0, col + runesWidth(c.line[:pos])
+	}
+
+	row, col = col/cols, col%cols
+
+	for _, r := range c.line[:pos] {
+
+		if r == '\n' {
+			row, col = row+1, 0
+			continue
+		}
+
+		w := runeWidth(r)
+		if col+w > cols {
+			row, col = row+1, 0
+		}
+		col += w
+
+		if col >= cols {
+			//the cursor goes to the next row once something is written
+			row, col = row+1, 0
+		}
+	}
+
+	return
+}
+
+//screen position of the cursor when it is at index pos of the line, that is
+//on the rune at pos, which may have been wrapped to the next row
+func (c *client) screenPos(pos int) (row, col int) {
+
+	row, col = c.writePos(pos)
+
+	if cols := c.cols(); cols > 0 && pos < len(c.line) && col+runeWidth(c.line[pos]) > cols {
+		row, col = row+1, 0
+	}
+
+	return
+}
+
+//move the cursor of the client from the position of rune index from to rune index to
+func (c *client) moveCursor(from, to int) {
+
+	if from == to {
+		return
+	}
+
+	if c.cols() <= 0 {
+		if to < from {
+			c.print(strings.Repeat("\b", runesWidth(c.line[to:from])))
+		} else {
+			c.print(string(c.line[from:to]))
+		}
+		return
+	}
+
+	fromRow, fromCol := c.screenPos(from)
+	toRow, toCol := c.screenPos(to)
+
+	c.moveCursorOnScreen(fromRow, fromCol, toRow, toCol)
+}
+
+func (c *client) moveCursorOnScreen(fromRow, fromCol, toRow, toCol int) {
+
+	var seq string
+
+	if toRow < fromRow {
+		seq += fmt.Sprintf("\x1b[%dA", fromRow-toRow)
+	} else if toRow > fromRow {
+		seq += fmt.Sprintf("\x1b[%dB", toRow-fromRow)
+	}
+
+	if toCol < fromCol {
+		seq += fmt.Sprintf("\x1b[%dD", fromCol-toCol)
+	} else if toCol > fromCol {
+		seq += fmt.Sprintf("\x1b[%dC", toCol-fromCol)
+	}
+
+	c.print(seq)
+}
+
+//move the cursor of the client from the cursor of the line to where the rune at index pos is written
+func (c *client) moveCursorToWrite(pos int) {
+
+	if c.cols() <= 0 {
+		c.moveCursor(c.lineCursor, pos)
+		return
+	}
+
+	fromRow, fromCol := c.screenPos(c.lineCursor)
+	toRow, toCol := c.writePos(pos)
+	c.moveCursorOnScreen(fromRow, fromCol, toRow, toCol)
+}
+
+//write the line from rune index start to the end, the cursor of the client is where start is written.
+//oldWidth is how many columns the replaced text took from start, it is wiped.
+//The cursor of the client is left at the end of line.
+func (c *client) writeTail(start int, oldWidth int) {
+
+	tail := c.line[start:]
+
+	if c.cols() <= 0 {
+		c.print(string(tail))
+		if wipe := oldWidth - runesWidth(tail); wipe > 0 {
+			c.print(strings.Repeat(" ", wipe) + strings.Repeat("\b", wipe))
+		}
+		return
+	}
+
+	//erase the old line from here, including cells skipped by wrapped wide runes
+	c.print("\x1b[J")
+	c.print(string(tail))
+
+	if _, col := c.writePos(len(c.line)); col == 0 && len(tail) != 0 {
+		//the last row is full, the terminal holds the cursor in the last column until
+		//something is written, move it to the next row to match writePos
+		c.print("\n")
+	}
+}
+
+//print the prompt and the line on a new row, e.g. after a listing
+func (c *client) redraw() {
+	c.printPrompt()
+	c.writeTail(0, 0)
+	c.moveCursor(len(c.line), c.lineCursor)
+}
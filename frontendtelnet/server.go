@@ -3,6 +3,7 @@ package frontendtelnet
 import (
 	"fmt"
 	"net"
+	"unicode"
 
 	"github.com/ershixiongTQL/cli-ui/frontendtelnet/protocol"
 
@@ -23,45 +24,52 @@ const (
 	ESC    uint8 = 0x1b
 	QM     uint8 = 0x3f
 	CTRL_A uint8 = 'A' - '@'
+	CTRL_B uint8 = 'B' - '@'
 	CTRL_E uint8 = 'E' - '@'
+	CTRL_F uint8 = 'F' - '@'
+	CTRL_K uint8 = 'K' - '@'
+	CTRL_L uint8 = 'L' - '@'
+	CTRL_N uint8 = 'N' - '@'
+	CTRL_P uint8 = 'P' - '@'
+	CTRL_T uint8 = 'T' - '@'
 	CTRL_U uint8 = 'U' - '@'
+	CTRL_W uint8 = 'W' - '@'
+	CTRL_Y uint8 = 'Y' - '@'
 )
 
-var triggerMap = map[uint8]uint8{
-	CR:     CR,
-	TAB:    TAB,
-	BS:     BS,
-	DEL:    DEL,
-	ETX:    ETX,
-	EOT:    EOT,
-	SUB:    SUB,
-	ESC:    ESC,
-	QM:     QM,
-	CTRL_A: CTRL_A,
-	CTRL_E: CTRL_E,
-	CTRL_U: CTRL_U,
-	NULL:   NULL,
-}
-
-func triggerCheck(char uint8) (isTrigger bool) {
-	_, isTrigger = triggerMap[char]
-	return
-}
-
 type Config struct {
 	GetPrompt func() string
 	GetBanner func() string
-	Backend   interfaces.BackEndInterface
-	ListenOn  string
+
+	//prompt of the following lines of a multi-line command, "> " if nil
+	GetContinuationPrompt func() string
+
+	Backend  interfaces.BackEndInterface
+	ListenOn string
+
+	KeyBindings KeyBindings //nil for DefaultKeyBindings
+
+	ListDescriptions bool //list the completions with their descriptions, one a line
+	ListQueryItems   int  //ask before listing more completions than this, 100 if 0, never if negative
+
+	//suggest the rest of the line from the history and the completions, dimmed after the cursor.
+	//Not for the terminals told as dumb.
+	Autosuggest bool
+
+	//color the line as it is typed, the keywords bold, the valid values green, the invalid token red.
+	//It is toggled in a session by ActionToggleHighlight. Not for the terminals told as dumb.
+	Highlight bool
 }
 
 type Server struct {
 	config   Config
+	keys     *keyTable
 	listener net.Listener
 }
 
 func (s *Server) Init(cfg Config) error {
 	s.config = cfg
+	s.keys = newKeyTable(cfg.KeyBindings)
 	return nil
 }
 
@@ -119,9 +127,14 @@ func telnetConnRoutine(conn *protocol.Conn, s *Server) {
 	client.print(s.config.GetBanner())
 	client.printPrompt()
 
+	readRune := func() (r rune, err error) {
+		r, _, err = conn.ReadRune()
+		return
+	}
+
 	for {
 
-		char, err := conn.ReadByte()
+		seq, action, bound, err := s.keys.read(readRune)
 
 		if err != nil {
 			//TODO: detach hook
@@ -129,134 +142,34 @@ func telnetConnRoutine(conn *protocol.Conn, s *Server) {
 			return
 		}
 
-		if !triggerCheck(char) {
-			if char == LF {
-				continue
-			}
-			client.lineAppend([]byte{char})
+		char := []rune(seq)
+
+		//the answer to a question asked, any other key is a no
+		if confirm := client.confirm; confirm != nil {
+			client.confirm = nil
+			confirm(seq == "y" || seq == "Y" || seq == " ")
+			client.decorate()
 			continue
 		}
 
-		switch char {
-		case CR:
-			client.print("\n")
-			if client.getLineLen() > 0 {
-				if err := client.exec(); err != nil {
-					client.close()
-					return
-				}
-			}
-			client.printPrompt()
-		case TAB:
-			client.getCompletions()
-		case QM:
-			client.getHelp()
-		case BS:
-			fallthrough
-		case DEL:
-			client.inLineBs()
-		case ESC:
-
-			var err error
-			var next uint8
-
-			next, err = conn.ReadByte()
-			if err != nil || next == ESC {
-				client.close()
-				return
-			}
+		client.eraseSuggestion()
 
-			switch next {
-			case ESC:
-				client.close()
-				return
+		printable := len(char) == 1 && char[0] != unicode.ReplacementChar && unicode.IsPrint(char[0])
 
-			case 0x5b:
-
-				next, err = conn.ReadByte()
-				if err != nil {
-					client.close()
-					return
-				}
-
-				switch next {
-				case 'A':
-					client.historyCheckout(true)
-				case 'B':
-					client.historyCheckout(false)
-				case 'C':
-					client.cursorMove(1) //right
-				case 'D':
-					client.cursorMove(-1) //left
-				case '1': //home
-					next, err = conn.ReadByte()
-					if err != nil {
-						client.close()
-						return
-					}
-
-					//fmt.Printf("escape 0x5b: 0x31: 0x%x\n", next)
-
-					switch next {
-					case 0x7e: //home
-						client.cursorHome()
-					}
-
-				case '4': //end
-					next, err = conn.ReadByte()
-					if err != nil {
-						client.close()
-						return
-					}
-
-					//fmt.Printf("escape 0x5b: 0x34: 0x%x\n", next)
-
-					switch next {
-					case 0x7e: //home
-						client.cursorEnd()
-					}
-
-				case '3':
-					next, err = conn.ReadByte()
-					if err != nil {
-						client.close()
-						return
-					}
-
-					//fmt.Printf("escape 0x5b: 0x33: 0x%x\n", next)
-
-					switch next {
-					case 0x7e: //del ahead
-						client.inlineDel()
-					}
-				default:
-
-				}
-
-			default:
-				fmt.Printf("unknown ESC next: %d(0x%x)\n", next, next)
+		//keys like '?' are taken literally in a heredoc body
+		if printable && (!bound || client.inHeredoc()) {
+			client.selfInsert(char)
+			client.decorate()
+			continue
+		}
+
+		if bound {
+			if err := client.do(action); err != nil {
 				client.close()
 				return
 			}
-
-		case ETX:
-			fallthrough
-		case EOT:
-			fallthrough
-		case SUB:
-			client.close()
-			return
-		case CTRL_A:
-			client.cursorHome()
-		case CTRL_E:
-			client.cursorEnd()
-		case CTRL_U:
-			client.allLineClear()
-		case NULL:
-			continue
-		default:
-			continue
 		}
 
+		client.decorate()
 	}
 }
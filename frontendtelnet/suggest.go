@@ -0,0 +1,106 @@
+package frontendtelnet
+
+import (
+	"strings"
+
+	"github.com/ershixiongTQL/cli-ui/interfaces"
+)
+
+//Suggestions of the rest of the line, shown dimmed after the cursor when it is at the end of the line.
+//The last command of the history starting with the line is suggested, or else what completes the
+//line for sure. They are accepted by moving the cursor forward, e.g. with right-arrow or Ctrl-F.
+//The suggestion is erased before a key is handled and found again after it.
+
+//terminals known not to take the ANSI sequences drawing the suggestions
+var dumbTerminals = []string{"dumb", "unknown"}
+
+func dumbTerminal(termType string) bool {
+	for _, t := range dumbTerminals {
+		if strings.EqualFold(termType, t) {
+			return true
+		}
+	}
+	return false
+}
+
+//are the suggestions shown to the client
+func (c *client) suggesting() bool {
+	return c.server.config.Autosuggest && !dumbTerminal(c.conn.TerminalType())
+}
+
+//the rest of the line suggested, "" if none
+func (c *client) findSuggestion() string {
+
+	line := c.getLine()
+	input := c.pending + line
+
+	if strings.TrimSpace(line) == "" || c.inHeredoc() {
+		return ""
+	}
+
+	if entry, found := c.history.Latest(input); found {
+		if rest := entry[len(input):]; !strings.Contains(rest, "\n") {
+			return rest
+		}
+	}
+
+	if completer := c.server.config.Backend.Completer; completer != nil {
+		completions, replace := completer(input)
+		if len(completions) == 1 && replace == 0 && completions[0].Kind != interfaces.COMPLETION_KIND_ARGUMENT {
+			if text := completions[0].Text; strings.TrimSpace(text) != "" {
+				return text
+			}
+		}
+	}
+
+	return ""
+}
+
+//find the suggestion for the line and show it after the cursor
+func (c *client) suggest() {
+
+	c.suggestion = ""
+
+	if !c.suggesting() || c.confirm != nil || !c.isCursorAtTheEnd() {
+		return
+	}
+
+	c.suggestion = c.findSuggestion()
+
+	shown := []rune(c.suggestion)
+
+	//only the part fitting on the row of the cursor
+	if cols := c.cols(); cols > 0 {
+		_, col := c.writePos(len(c.line))
+		for len(shown) != 0 && col+runesWidth(shown) >= cols {
+			shown = shown[:len(shown)-1]
+		}
+	}
+
+	if width := runesWidth(shown); width != 0 {
+		c.print("\x1b[2m" + string(shown) + "\x1b[0m" + strings.Repeat("\b", width))
+		c.suggestionShown = true
+	}
+}
+
+//erase the suggestion shown, it is kept to be accepted
+func (c *client) eraseSuggestion() {
+
+	if c.suggestionShown {
+		c.print("\x1b[K")
+		c.suggestionShown = false
+	}
+}
+
+//add the suggestion to the line, false if there is none
+func (c *client) acceptSuggestion() bool {
+
+	if c.suggestion == "" || !c.isCursorAtTheEnd() {
+		return false
+	}
+
+	c.lineAppend([]rune(c.suggestion))
+	c.suggestion = ""
+
+	return true
+}
@@ -0,0 +1,70 @@
+package frontendtelnet
+
+import (
+	"testing"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+	"github.com/ershixiongTQL/cli-ui/frontendtelnet"
+	"github.com/ershixiongTQL/cli-ui/interfaces"
+)
+
+const testSchema = `{
+  "commands": [
+    {"name": "show interface", "prefix": "show interface",
+     "param": [
+       {"name": "name", "type": "plain"},
+       {"name": "detail", "type": "selection", "range": ["brief", "detail"], "condition": ["- eq name"], "optional": true}
+     ]},
+    {"name": "shutdown", "prefix": "shutdown"}
+  ]
+}`
+
+//a backend completing by a schema, as the one of the module does
+type schemaBackend struct {
+	testBackend
+	completer completer.Completer
+}
+
+func (b *schemaBackend) Completer(input string) (completions []interfaces.Completion, replace int) {
+
+	candidates, replace := b.completer.Complete(input)
+
+	for _, c := range candidates {
+		completions = append(completions, interfaces.Completion{Text: c.Text, Display: c.Value, Description: c.Description})
+	}
+
+	return
+}
+
+func (b *schemaBackend) Executable(input string) bool {
+	return b.completer.Executable(input)
+}
+
+func startSchemaSession(t *testing.T, width, height int) (s *session, stop func()) {
+
+	backend := new(schemaBackend)
+	if err := backend.completer.SetupBytes([]byte(testSchema), completer.FormatJSON); err != nil {
+		t.Fatal(err)
+	}
+
+	return startSessionWith(t, width, height, func(cfg *frontendtelnet.Config) { cfg.Backend = backend })
+}
+
+//an abbreviation of more than one command does not run, <cr> is not offered
+func TestAmbiguousPrefixListing(t *testing.T) {
+
+	s, stop := startSchemaSession(t, 40, 10)
+	defer stop()
+
+	s.keys("sh\t")
+	s.expect(t, []string{"R# sh", "show      shutdown", "R# sh"}, 2, 5)
+}
+
+func TestExecutableListing(t *testing.T) {
+
+	s, stop := startSchemaSession(t, 40, 10)
+	defer stop()
+
+	s.keys("show interface eth0 \t")
+	s.expect(t, []string{"R# show interface eth0", "brief   detail  <cr>", "R# show interface eth0"}, 2, 23)
+}
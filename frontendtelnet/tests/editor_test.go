@@ -0,0 +1,133 @@
+package frontendtelnet
+
+import (
+	"testing"
+)
+
+func TestWordMoves(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	//Alt-F to the end of the words, Alt-B to the start of one
+	s.keys("show interface eth0\x01\x1bf\x1bf")
+	s.expect(t, []string{"R# show interface eth0"}, 0, 17)
+
+	s.keys("\x1bbX")
+	s.expect(t, []string{"R# show Xinterface eth0"}, 0, 9)
+
+	//words are made of letters and digits
+	s.keys("\x05\x1bb\x1bb")
+	s.expect(t, []string{"R# show Xinterface eth0"}, 0, 8)
+}
+
+func TestKillLine(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	//Ctrl-K kills to the end of the line, Ctrl-Y yanks it back
+	s.keys("show interface eth0\x01\x1bf\x0b")
+	s.expect(t, []string{"R# show"}, 0, 7)
+
+	s.keys("\x01\x19")
+	s.expect(t, []string{"R#  interface eth0show"}, 0, 18)
+
+	//Ctrl-U kills to the start of the line
+	s.keys("\x15")
+	s.expect(t, []string{"R# show"}, 0, 3)
+
+	s.keys("\x05\x19")
+	s.expect(t, []string{"R# show interface eth0"}, 0, 22)
+}
+
+func TestKillWord(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	//Alt-D kills the word after the cursor
+	s.keys("show interface eth0\x01\x1bd")
+	s.expect(t, []string{"R#  interface eth0"}, 0, 3)
+
+	//Ctrl-W kills the whitespace delimited word before the cursor
+	s.keys("\x05\x17")
+	s.expect(t, []string{"R#  interface"}, 0, 14)
+
+	s.keys("\x19")
+	s.expect(t, []string{"R#  interface eth0"}, 0, 18)
+}
+
+//consecutive kills make one entry of the kill ring, in the order of the line
+func TestKillRingMerge(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	s.keys("show interface eth0\x01\x1bd\x1bd")
+	s.expect(t, []string{"R#  eth0"}, 0, 3)
+
+	s.keys("\x05\x19")
+	s.expect(t, []string{"R#  eth0show interface"}, 0, 22)
+
+	s.keys("\x15show interface eth0\x17\x17")
+	s.expect(t, []string{"R# show"}, 0, 8)
+
+	s.keys("\x19")
+	s.expect(t, []string{"R# show interface eth0"}, 0, 22)
+}
+
+//kills apart are entries of their own, Alt-Y after a yank replaces it with the previous one
+func TestYankPop(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	s.keys("one two\x17\x01\x0b")
+	s.expect(t, []string{"R#"}, 0, 3)
+
+	s.keys("\x19")
+	s.expect(t, []string{"R# one"}, 0, 7)
+
+	s.keys("\x1by")
+	s.expect(t, []string{"R# two"}, 0, 6)
+
+	s.keys("\x1by")
+	s.expect(t, []string{"R# one"}, 0, 7)
+
+	//not after a yank, it does nothing
+	s.keys("x\x1by")
+	s.expect(t, []string{"R# one x"}, 0, 8)
+}
+
+func TestTransposeChars(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	//the characters around the cursor are swapped
+	s.keys("sohw\x02\x02\x14")
+	s.expect(t, []string{"R# show"}, 0, 6)
+
+	//the last two ones at the end of the line
+	s.keys("\x05 vlna\x14")
+	s.expect(t, []string{"R# show vlan"}, 0, 12)
+
+	//nothing at the start of the line
+	s.keys("\x01\x14")
+	s.expect(t, []string{"R# show vlan"}, 0, 3)
+}
+
+func TestClearScreen(t *testing.T) {
+
+	s, stop := startSession(t, 40, 10)
+	defer stop()
+
+	s.keys("first\r")
+	s.keys("show\x02")
+	s.expect(t, []string{"R# first", "ok", "R# show"}, 2, 6)
+
+	//the prompt and the line are drawn again at the top, the cursor where it was in the line
+	s.keys("\x0c")
+	s.expect(t, []string{"R# show"}, 0, 6)
+}
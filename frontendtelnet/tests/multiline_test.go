@@ -0,0 +1,107 @@
+package frontendtelnet
+
+import (
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/ershixiongTQL/cli-ui/frontendtelnet"
+)
+
+//a backend keeping the command lines it is given
+type recordBackend struct {
+	basicBackend
+	lock  sync.Mutex
+	lines []string
+}
+
+func (b *recordBackend) CommandHandler(command string, resultIO io.StringWriter) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.lines = append(b.lines, command)
+	resultIO.WriteString("ok")
+	return nil
+}
+
+func (b *recordBackend) expect(t *testing.T, lines ...string) {
+	t.Helper()
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if !reflect.DeepEqual(b.lines, lines) {
+		t.Fatalf("commands %q, expected %q", b.lines, lines)
+	}
+}
+
+func startRecordSession(t *testing.T, setup func(cfg *frontendtelnet.Config)) (s *session, backend *recordBackend, stop func()) {
+
+	backend = new(recordBackend)
+
+	s, stop = startSessionWith(t, 40, 10, func(cfg *frontendtelnet.Config) {
+		cfg.Backend = backend
+		if setup != nil {
+			setup(cfg)
+		}
+	})
+
+	return
+}
+
+//a line ending with a backslash goes on with the next one, the command is one line in the history
+func TestBackslashContinuation(t *testing.T) {
+
+	s, backend, stop := startRecordSession(t, nil)
+	defer stop()
+
+	s.keys("show \\\r")
+	s.expect(t, []string{"R# show \\", ">"}, 1, 2)
+	backend.expect(t)
+
+	s.keys("vlan 10\r")
+	s.expect(t, []string{"R# show \\", "> vlan 10", "ok", "R#"}, 3, 3)
+	backend.expect(t, "show vlan 10")
+
+	s.keys("\x1b[A")
+	s.expect(t, []string{"R# show \\", "> vlan 10", "ok", "R# show vlan 10"}, 3, 15)
+
+	//an escaped backslash does not continue the line
+	s.keys("\x15show \\\\\r")
+	backend.expect(t, "show vlan 10", `show \\`)
+}
+
+//a line with a quote not closed goes on with the next one, the line break is in the quote
+func TestUnclosedQuoteContinuation(t *testing.T) {
+
+	s, backend, stop := startRecordSession(t, nil)
+	defer stop()
+
+	s.keys("desc \"two\r")
+	s.expect(t, []string{"R# desc \"two", ">"}, 1, 2)
+
+	s.keys("lines\"\r")
+	s.expect(t, []string{"R# desc \"two", "> lines\"", "ok", "R#"}, 3, 3)
+	backend.expect(t, "desc \"two\nlines\"")
+}
+
+//the body lines of a heredoc are taken as they are until the terminator line
+func TestHeredoc(t *testing.T) {
+
+	s, backend, stop := startRecordSession(t, func(cfg *frontendtelnet.Config) {
+		cfg.GetContinuationPrompt = func() string { return "... " }
+	})
+	defer stop()
+
+	s.keys("import <<EOF\r")
+	s.expect(t, []string{"R# import <<EOF", "..."}, 1, 4)
+
+	//neither continued by a backslash nor by a quote
+	s.keys("a \"b\\\r")
+	s.expect(t, []string{"R# import <<EOF", "... a \"b\\", "..."}, 2, 4)
+	backend.expect(t)
+
+	s.keys("  EOF \r")
+	s.expect(t, []string{"R# import <<EOF", "... a \"b\\", "...   EOF", "ok", "R#"}, 4, 3)
+	backend.expect(t, "import <<EOF\na \"b\\\n  EOF ")
+}
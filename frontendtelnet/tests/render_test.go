@@ -0,0 +1,390 @@
+package frontendtelnet
+
+import (
+	"io"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/ershixiongTQL/cli-ui/frontendtelnet"
+	"github.com/ershixiongTQL/cli-ui/interfaces"
+)
+
+//a backend with the methods a backend is required to have
+type basicBackend struct{}
+
+//completions of "v", many of them for "n"
+func (b *basicBackend) Completer(input string) (completions []interfaces.Completion, replace int) {
+	switch input {
+	case "v":
+		for _, v := range []string{"vlan", "vrf", "vty", "version", "vxlan"} {
+			completions = append(completions, interfaces.Completion{Text: v[1:] + " ", Display: v})
+		}
+	case "n":
+		for i := 0; i < 120; i++ {
+			completions = append(completions, interfaces.Completion{Text: "x ", Display: "n"})
+		}
+	}
+	return
+}
+
+func (b *basicBackend) Helps(input string) (help string)             { return "" }
+func (b *basicBackend) UserAuth(username string, passwd string) bool { return true }
+
+func (b *basicBackend) CommandHandler(command string, resultIO io.StringWriter) error {
+	resultIO.WriteString("ok")
+	return nil
+}
+
+//a backend with the optional methods too
+type testBackend struct {
+	basicBackend
+}
+
+func (b *testBackend) Executable(input string) bool { return true }
+
+//"show" is a keyword, "up" is a value, "bad" is invalid, any other word is an argument
+func (b *testBackend) Classify(input string) (tokens []interfaces.Token) {
+	start := -1
+	for i := 0; i <= len(input); i++ {
+		if i < len(input) && input[i] != ' ' {
+			if start < 0 {
+				start = i
+			}
+			continue
+		}
+		if start >= 0 {
+			tokens = append(tokens, interfaces.Token{Start: start, End: i, Class: tokenClass(input[start:i])})
+			start = -1
+		}
+	}
+	return
+}
+
+func tokenClass(word string) interfaces.TOKEN_CLASS {
+	switch word {
+	case "show":
+		return interfaces.TOKEN_CLASS_KEYWORD
+	case "up":
+		return interfaces.TOKEN_CLASS_VALUE
+	case "bad":
+		return interfaces.TOKEN_CLASS_INVALID
+	}
+	return interfaces.TOKEN_CLASS_ARGUMENT
+}
+
+type session struct {
+	conn net.Conn
+	term *vt
+}
+
+//start a server and connect to it with a terminal of the given size, width 0 for a client without NAWS
+func startSession(t *testing.T, width, height int) (s *session, stop func()) {
+	return startSessionWith(t, width, height, nil)
+}
+
+//start a session with the server configured by setup
+func startSessionWith(t *testing.T, width, height int, setup func(cfg *frontendtelnet.Config)) (s *session, stop func()) {
+
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	cfg := frontendtelnet.Config{
+		GetPrompt: func() string { return "R" },
+		GetBanner: func() string { return "" },
+		Backend:   &testBackend{},
+		ListenOn:  addr,
+	}
+	if setup != nil {
+		setup(&cfg)
+	}
+
+	server := frontendtelnet.Server{}
+	server.Init(cfg)
+
+	if err := server.Start(); err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rows := height
+	if rows == 0 {
+		rows = 24
+	}
+	cols := width
+	if cols == 0 {
+		cols = 200
+	}
+
+	s = &session{conn: conn, term: newVT(rows, cols)}
+
+	go io.Copy(s.term, conn)
+
+	if width != 0 {
+		//IAC WILL NAWS, IAC SB NAWS <width> <height> IAC SE
+		conn.Write([]byte{255, 251, 31, 255, 250, 31, byte(width >> 8), byte(width), byte(height >> 8), byte(height), 255, 240})
+	}
+
+	s.settle()
+
+	return s, func() {
+		conn.Close()
+		server.Stop()
+	}
+}
+
+//wait until the server stops writing
+func (s *session) settle() {
+	last := len(s.term.screen())
+	for i := 0; i < 100; i++ {
+		time.Sleep(20 * time.Millisecond)
+		s.term.lock.Lock()
+		pending := len(s.term.raw)
+		s.term.lock.Unlock()
+		now := len(s.term.screen())
+		if now == last && pending == 0 && i > 2 {
+			return
+		}
+		last = now
+	}
+}
+
+func (s *session) keys(keys string) {
+	s.conn.Write([]byte(keys))
+	s.settle()
+}
+
+func (s *session) expect(t *testing.T, screen []string, row, col int) {
+	t.Helper()
+
+	if got := s.term.screen(); !reflect.DeepEqual(got, screen) {
+		t.Fatalf("screen:\n%q\nexpected:\n%q", got, screen)
+	}
+
+	if r, c := s.term.cursor(); r != row || c != col {
+		t.Fatalf("cursor at %d,%d, expected %d,%d", r, c, row, col)
+	}
+}
+
+//the runes of the row from column from to column to, not included, have the SGR attributes
+func (s *session) expectAttr(t *testing.T, row, from, to int, attr string) {
+	t.Helper()
+
+	for col := from; col < to; col++ {
+		if got := s.term.attr(row, col); got != attr {
+			t.Fatalf("attributes %q at %d,%d, expected %q", got, row, col, attr)
+		}
+	}
+}
+
+func TestWrappedLine(t *testing.T) {
+
+	s, stop := startSession(t, 20, 10)
+	defer stop()
+
+	s.keys("abcdefghijklmnopqrstuvwxyz0123")
+	s.expect(t, []string{"R# abcdefghijklmnopq", "rstuvwxyz0123"}, 1, 13)
+
+	//insert at the beginning, the text flows into the next row
+	s.keys("\x01X")
+	s.expect(t, []string{"R# Xabcdefghijklmnop", "qrstuvwxyz0123"}, 0, 4)
+
+	//left over the row boundary
+	s.keys("\x05\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D\x1b[D")
+	s.expect(t, []string{"R# Xabcdefghijklmnop", "qrstuvwxyz0123"}, 0, 19)
+
+	//delete makes the second row shorter
+	s.keys("\x1b[3~\x1b[3~")
+	s.expect(t, []string{"R# Xabcdefghijklmnor", "stuvwxyz0123"}, 0, 19)
+
+	//kill the line, the second row is wiped
+	s.keys("\x05\x15")
+	s.expect(t, []string{"R#"}, 0, 3)
+}
+
+func TestFullRow(t *testing.T) {
+
+	s, stop := startSession(t, 10, 10)
+	defer stop()
+
+	s.keys("abcdefg")
+	s.expect(t, []string{"R# abcdefg"}, 1, 0)
+
+	s.keys("h")
+	s.expect(t, []string{"R# abcdefg", "h"}, 1, 1)
+
+	s.keys("\x7f\x7f")
+	s.expect(t, []string{"R# abcdef"}, 0, 9)
+
+	s.keys("\x01")
+	s.expect(t, []string{"R# abcdef"}, 0, 3)
+}
+
+func TestWideRuneWrap(t *testing.T) {
+
+	s, stop := startSession(t, 10, 10)
+	defer stop()
+
+	//no room for a wide rune in the last column, it goes to the next row
+	s.keys("abcdef中文")
+	s.expect(t, []string{"R# abcdef", "中文"}, 1, 4)
+
+	s.keys("\x02\x02")
+	s.expect(t, []string{"R# abcdef", "中文"}, 1, 0)
+
+	s.keys("\x02")
+	s.expect(t, []string{"R# abcdef", "中文"}, 0, 8)
+
+	//fill the last column, the wide runes stay on the next row
+	s.keys("\x06X")
+	s.expect(t, []string{"R# abcdefX", "中文"}, 1, 0)
+
+	s.keys("Y")
+	s.expect(t, []string{"R# abcdefX", "Y中文"}, 1, 1)
+
+	s.keys("\x7f\x7f")
+	s.expect(t, []string{"R# abcdef", "中文"}, 1, 0)
+}
+
+func TestMultipleLines(t *testing.T) {
+
+	s, stop := startSession(t, 20, 10)
+	defer stop()
+
+	s.keys("abcdefghijklmnopqrstuvwxyz\x01\r")
+	s.expect(t, []string{"R# abcdefghijklmnopq", "rstuvwxyz", "ok", "R#"}, 3, 3)
+}
+
+func TestWithoutWindowSize(t *testing.T) {
+
+	s, stop := startSession(t, 0, 0)
+	defer stop()
+
+	s.keys("show 中文 interface\x01\x06\x06\x06\x06\x06X\x05\x7f")
+	s.expect(t, []string{"R# show X中文 interfac"}, 0, 22)
+}
+
+func TestCompletionColumns(t *testing.T) {
+
+	s, stop := startSession(t, 20, 10)
+	defer stop()
+
+	s.keys("v\t")
+	s.expect(t, []string{"R# v", "vlan     version", "vrf      vxlan", "vty      <cr>", "R# v"}, 4, 4)
+}
+
+//no <cr> is offered by a backend not telling if a line is executable
+func TestCompletionNotExecutable(t *testing.T) {
+
+	s, stop := startSessionWith(t, 20, 10, func(cfg *frontendtelnet.Config) { cfg.Backend = &basicBackend{} })
+	defer stop()
+
+	s.keys("v\t")
+	s.expect(t, []string{"R# v", "vlan     version", "vrf      vxlan", "vty", "R# v"}, 4, 4)
+}
+
+func TestCompletionQuery(t *testing.T) {
+
+	s, stop := startSession(t, 80, 10)
+	defer stop()
+
+	s.keys("n\t")
+	s.expect(t, []string{"R# n", "Display all 121 possibilities? (y or n)"}, 1, 39)
+
+	s.keys("n")
+	s.expect(t, []string{"R# n", "Display all 121 possibilities? (y or n)", "R# n"}, 2, 4)
+}
+
+func TestSuggestion(t *testing.T) {
+
+	s, stop := startSessionWith(t, 20, 10, func(cfg *frontendtelnet.Config) { cfg.Autosuggest = true })
+	defer stop()
+
+	s.keys("show vlan\r")
+	s.keys("sh")
+	s.expect(t, []string{"R# show vlan", "ok", "R# show vlan"}, 2, 5)
+
+	s.keys("\x1b[C")
+	s.expect(t, []string{"R# show vlan", "ok", "R# show vlan"}, 2, 12)
+
+	//erased once the line does not match
+	s.keys("\x7f\x7f\x7f\x7fx")
+	s.expect(t, []string{"R# show vlan", "ok", "R# show x"}, 2, 9)
+}
+
+func TestSuggestionDumbTerminal(t *testing.T) {
+
+	s, stop := startSessionWith(t, 20, 10, func(cfg *frontendtelnet.Config) { cfg.Autosuggest = true })
+	defer stop()
+
+	//IAC WILL TTYPE, IAC SB TTYPE IS "dumb" IAC SE
+	s.conn.Write([]byte{255, 251, 24})
+	s.keys(string([]byte{255, 250, 24, 0, 'd', 'u', 'm', 'b', 255, 240}))
+
+	s.keys("show vlan\rsh")
+	s.expect(t, []string{"R# show vlan", "ok", "R# sh"}, 2, 5)
+}
+
+func TestHighlight(t *testing.T) {
+
+	s, stop := startSessionWith(t, 20, 10, func(cfg *frontendtelnet.Config) { cfg.Highlight = true })
+	defer stop()
+
+	s.keys("show up intface bad xyz\x1b[D\x1b[D")
+	s.expect(t, []string{"R# show up intface b", "ad xyz"}, 1, 4)
+
+	//the keyword bold, the value green, the invalid token red, even wrapped, the argument as it is
+	s.expectAttr(t, 0, 0, 3, "")
+	s.expectAttr(t, 0, 3, 7, "1")
+	s.expectAttr(t, 0, 7, 8, "")
+	s.expectAttr(t, 0, 8, 10, "32")
+	s.expectAttr(t, 0, 10, 19, "")
+	s.expectAttr(t, 0, 19, 20, "31")
+	s.expectAttr(t, 1, 0, 2, "31")
+	s.expectAttr(t, 1, 2, 6, "")
+
+	//toggled off, the line stays where it is, uncolored
+	s.keys("\x1bh\x02")
+	s.expect(t, []string{"R# show up intface b", "ad xyz"}, 1, 3)
+	s.expectAttr(t, 0, 0, 20, "")
+	s.expectAttr(t, 1, 0, 6, "")
+
+	s.keys("\x1bh\r")
+	s.expect(t, []string{"R# show up intface b", "ad xyz", "ok", "R#"}, 3, 3)
+}
+
+//a backend not classifying the tokens leaves the line as it is
+func TestHighlightNotClassified(t *testing.T) {
+
+	s, stop := startSessionWith(t, 20, 10, func(cfg *frontendtelnet.Config) {
+		cfg.Highlight = true
+		cfg.Backend = &basicBackend{}
+	})
+	defer stop()
+
+	s.keys("show up bad")
+	s.expect(t, []string{"R# show up bad"}, 0, 14)
+	s.expectAttr(t, 0, 0, 14, "")
+
+	s.keys("\r")
+	s.expect(t, []string{"R# show up bad", "ok", "R#"}, 2, 3)
+}
+
+//PageUp, Insert and Shift-Right are not bound, nothing of them is inserted
+func TestUnboundControlSequence(t *testing.T) {
+
+	s, stop := startSession(t, 20, 10)
+	defer stop()
+
+	s.keys("ab\x1b[5~\x1b[2~\x1b[1;2Cc\x1b[D\x1b[6~d")
+	s.expect(t, []string{"R# abdc"}, 0, 6)
+}
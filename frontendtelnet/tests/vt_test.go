@@ -0,0 +1,262 @@
+package frontendtelnet
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+//vt is a small virtual terminal, it understands what the line editor sends:
+//printable runes with auto wrap, CR, LF, BS, the telnet commands, the ANSI
+//cursor movement / erase sequences and the SGR attributes of the runes.
+type vt struct {
+	lock        sync.Mutex
+	rows, cols  int
+	cells       [][]rune
+	attrs       [][]string //the SGR parameters each rune is written with, e.g. "1" or "32"
+	row, col    int
+	pendingWrap bool
+	sgr         string //the SGR parameters in effect, "" if reset
+
+	raw []byte //not yet decoded input
+}
+
+func newVT(rows, cols int) (t *vt) {
+	t = &vt{rows: rows, cols: cols}
+	for i := 0; i < rows; i++ {
+		t.cells = append(t.cells, t.blankRow())
+		t.attrs = append(t.attrs, make([]string, cols))
+	}
+	return
+}
+
+func (t *vt) blankRow() []rune {
+	row := make([]rune, t.cols)
+	for i := range row {
+		row[i] = ' '
+	}
+	return row
+}
+
+func vtRuneWidth(r rune) int {
+	if (r >= 0x1100 && r <= 0x115f) || (r >= 0x2e80 && r <= 0xa4cf) || (r >= 0xac00 && r <= 0xd7a3) ||
+		(r >= 0xf900 && r <= 0xfaff) || (r >= 0xff00 && r <= 0xff60) || (r >= 0xffe0 && r <= 0xffe6) {
+		return 2
+	}
+	return 1
+}
+
+func (t *vt) Write(data []byte) (int, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.raw = append(t.raw, data...)
+
+	for len(t.raw) > 0 {
+		n := t.consume(t.raw)
+		if n == 0 {
+			break //incomplete sequence, wait for more data
+		}
+		t.raw = t.raw[n:]
+	}
+
+	return len(data), nil
+}
+
+//consume one unit of input, returns the number of bytes used, 0 if more data is needed
+func (t *vt) consume(b []byte) int {
+
+	switch b[0] {
+	case 0xff: //telnet IAC
+		if len(b) < 2 {
+			return 0
+		}
+		switch b[1] {
+		case 251, 252, 253, 254: //WILL WONT DO DONT
+			if len(b) < 3 {
+				return 0
+			}
+			return 3
+		case 250: //SB ... IAC SE
+			if i := strings.Index(string(b), "\xff\xf0"); i >= 0 {
+				return i + 2
+			}
+			return 0
+		case 0xff:
+			return 2
+		default:
+			return 2
+		}
+	case 0x1b:
+		return t.escape(b)
+	case '\r':
+		t.col, t.pendingWrap = 0, false
+	case '\n':
+		t.lineFeed()
+		t.pendingWrap = false
+	case '\b':
+		t.pendingWrap = false
+		if t.col > 0 {
+			t.col--
+		}
+	case 0x07, 0x0c: //BEL, FF
+	default:
+		if !utf8.FullRune(b) {
+			return 0
+		}
+		r, size := utf8.DecodeRune(b)
+		t.put(r)
+		return size
+	}
+
+	return 1
+}
+
+func (t *vt) escape(b []byte) int {
+
+	if len(b) < 2 {
+		return 0
+	}
+
+	if b[1] != '[' {
+		return 2
+	}
+
+	for i := 2; i < len(b); i++ {
+
+		if (b[i] >= '0' && b[i] <= '9') || b[i] == ';' || b[i] == '?' {
+			continue
+		}
+
+		params := strings.Split(string(b[2:i]), ";")
+		n, err := strconv.Atoi(params[0])
+		if err != nil || n == 0 {
+			n = 1
+		}
+
+		t.pendingWrap = false
+
+		switch b[i] {
+		case 'A':
+			t.row = max(t.row-n, 0)
+		case 'B':
+			t.row = min(t.row+n, t.rows-1)
+		case 'C':
+			t.col = min(t.col+n, t.cols-1)
+		case 'D':
+			t.col = max(t.col-n, 0)
+		case 'H':
+			t.row, t.col = 0, 0
+		case 'J':
+			from := t.row + 1
+			if params[0] == "2" {
+				from = 0
+			} else {
+				t.eraseRow(t.col)
+			}
+			for r := from; r < t.rows; r++ {
+				t.cells[r] = t.blankRow()
+				t.attrs[r] = make([]string, t.cols)
+			}
+		case 'K':
+			t.eraseRow(t.col)
+		case 'm':
+			if t.sgr = string(b[2:i]); t.sgr == "0" {
+				t.sgr = ""
+			}
+		}
+
+		return i + 1
+	}
+
+	return 0
+}
+
+func (t *vt) eraseRow(from int) {
+	for c := from; c < t.cols; c++ {
+		t.cells[t.row][c] = ' '
+		t.attrs[t.row][c] = ""
+	}
+}
+
+func (t *vt) lineFeed() {
+	if t.row+1 < t.rows {
+		t.row++
+		return
+	}
+	t.cells = append(t.cells[1:], t.blankRow())
+	t.attrs = append(t.attrs[1:], make([]string, t.cols))
+}
+
+func (t *vt) put(r rune) {
+
+	w := vtRuneWidth(r)
+
+	if t.pendingWrap || t.col+w > t.cols {
+		t.col, t.pendingWrap = 0, false
+		t.lineFeed()
+	}
+
+	t.cells[t.row][t.col] = r
+	t.attrs[t.row][t.col] = t.sgr
+	if w == 2 {
+		t.cells[t.row][t.col+1] = 0
+	}
+
+	t.col += w
+	if t.col >= t.cols {
+		t.col = t.cols - 1
+		t.pendingWrap = true
+	}
+}
+
+//the text on the screen, trailing blanks and rows are dropped
+func (t *vt) screen() (rows []string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	for _, row := range t.cells {
+		rows = append(rows, strings.TrimRight(strings.ReplaceAll(string(row), "\x00", ""), " "))
+	}
+
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+
+	return
+}
+
+//the SGR parameters of the rune at the position, "" if it has none
+func (t *vt) attr(row, col int) string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	return t.attrs[row][col]
+}
+
+//the position the next rune would be written to
+func (t *vt) cursor() (row, col int) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if t.pendingWrap {
+		return t.row + 1, 0
+	}
+
+	return t.row, t.col
+}
+
+func max(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,60 @@
+package frontendtelnet
+
+import "unicode"
+
+//East Asian Wide (W) and Fullwidth (F) blocks, they take two columns on terminals
+var wideRanges = [][2]rune{
+	{0x1100, 0x115f},   //Hangul Jamo
+	{0x2e80, 0x303e},   //CJK Radicals ... CJK Symbols and Punctuation
+	{0x3041, 0x33ff},   //Hiragana ... CJK Compatibility
+	{0x3400, 0x4dbf},   //CJK Unified Ideographs Extension A
+	{0x4e00, 0x9fff},   //CJK Unified Ideographs
+	{0xa000, 0xa4cf},   //Yi Syllables, Yi Radicals
+	{0xa960, 0xa97f},   //Hangul Jamo Extended-A
+	{0xac00, 0xd7a3},   //Hangul Syllables
+	{0xf900, 0xfaff},   //CJK Compatibility Ideographs
+	{0xfe10, 0xfe19},   //Vertical Forms
+	{0xfe30, 0xfe6f},   //CJK Compatibility Forms, Small Form Variants
+	{0xff00, 0xff60},   //Fullwidth Forms
+	{0xffe0, 0xffe6},   //Fullwidth Signs
+	{0x1f300, 0x1f64f}, //Misc Symbols and Pictographs, Emoticons
+	{0x1f900, 0x1f9ff}, //Supplemental Symbols and Pictographs
+	{0x20000, 0x2fffd}, //CJK Unified Ideographs Extension B ...
+	{0x30000, 0x3fffd}, //CJK Unified Ideographs Extension G ...
+}
+
+//runeWidth returns the number of terminal columns taken by the rune
+func runeWidth(r rune) int {
+
+	if r < 0x20 || r == 0x7f {
+		return 0
+	}
+
+	if r < 0x1100 && !unicode.Is(unicode.Mn, r) {
+		return 1
+	}
+
+	if unicode.Is(unicode.Mn, r) || unicode.Is(unicode.Me, r) || r == 0x200b {
+		//combining marks and zero width space
+		return 0
+	}
+
+	for _, wr := range wideRanges {
+		if r < wr[0] {
+			break
+		}
+		if r <= wr[1] {
+			return 2
+		}
+	}
+
+	return 1
+}
+
+//runesWidth returns the number of terminal columns taken by the runes
+func runesWidth(runes []rune) (width int) {
+	for _, r := range runes {
+		width += runeWidth(r)
+	}
+	return
+}
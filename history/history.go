@@ -54,13 +54,33 @@ func (h *HRing) Read() string {
 
 	if h.curr_pos < 0 {
 		return ""
-	} else if h.curr_pos < (h.last_index + 1) {
-		return (*h.histories)[h.last_index-h.curr_pos]
+	}
+
+	return h.at(h.curr_pos)
+}
+
+//the entry pos entries back from the last one
+func (h *HRing) at(pos int) string {
+
+	if pos < (h.last_index + 1) {
+		return (*h.histories)[h.last_index-pos]
 	} else {
-		return (*h.histories)[h.capacity-(h.curr_pos-h.last_index)]
+		return (*h.histories)[h.capacity-(pos-h.last_index)]
 	}
 }
 
+//Latest returns the last entry starting with prefix and longer than it, the position is kept
+func (h *HRing) Latest(prefix string) (entry string, found bool) {
+
+	for pos := 0; pos < h.cnt; pos++ {
+		if entry = h.at(pos); len(entry) > len(prefix) && strings.HasPrefix(entry, prefix) {
+			return entry, true
+		}
+	}
+
+	return "", false
+}
+
 func (h *HRing) Append(content string) {
 
 	tempComStr := strings.ReplaceAll(strings.ReplaceAll(content, " ", ""), "\n", "")
@@ -15,9 +15,64 @@ type UIAgentInterface interface {
 	Stop()
 }
 
+type COMPLETION_KIND uint
+
+const (
+	COMPLETION_KIND_KEYWORD  = iota //a keyword of a command
+	COMPLETION_KIND_VALUE           //a value of a param to choose from
+	COMPLETION_KIND_ARGUMENT        //what a param takes, e.g. "<name>"
+)
+
+//Completion is a way to complete the command line
+type Completion struct {
+	Text        string //what is added to the line, or replaces the end of it
+	Display     string //how it is listed, Text if ""
+	Description string
+	Kind        COMPLETION_KIND
+}
+
+type TOKEN_CLASS uint
+
+const (
+	TOKEN_CLASS_KEYWORD   = iota //a keyword of a command
+	TOKEN_CLASS_VALUE            //a valid value of a param to choose from, or a number in range
+	TOKEN_CLASS_ARGUMENT         //the value of a param taking any
+	TOKEN_CLASS_INVALID          //the first token no command takes
+	TOKEN_CLASS_UNCHECKED        //a token after an invalid one
+)
+
+//Token is a field of the command line and what it is taken as
+type Token struct {
+	Start int //byte offset of the field in the line
+	End   int //byte offset after the field
+	Class TOKEN_CLASS
+}
+
 type BackEndInterface interface {
-	Completer(input string) (completions []string)
+	//the completions of the input, if there are more than one they are listed. replace is how many
+	//bytes at the end of input the completions replace, 0 if they are added to it.
+	Completer(input string) (completions []Completion, replace int)
 	Helps(input string) (help string)
 	CommandHandler(command string, resultIO io.StringWriter) error
 	UserAuth(username string, passwd string) bool
 }
+
+//ExecutableChecker is implemented by a backend telling if a command line can be executed as it is,
+//for <cr> to be offered along with its completions. It is not offered for a backend not implementing it.
+type ExecutableChecker interface {
+	Executable(input string) bool
+}
+
+//Classifier is implemented by a backend telling what the tokens of a command line are taken as,
+//for the line to be highlighted. A line is not highlighted for a backend not implementing it.
+type Classifier interface {
+	Classify(input string) (tokens []Token)
+}
+
+//PositionedError is an error a command handler returns instead of printing it, when it is about
+//a position of the command line, e.g. a syntax error. The frontend prints a '^' marker under
+//the position followed by the error message.
+type PositionedError interface {
+	error
+	Offset() int //byte offset in the command line, -1 for no marker
+}
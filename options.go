@@ -0,0 +1,103 @@
+package cliui
+
+import (
+	"io"
+	"io/fs"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+)
+
+type options struct {
+	setupSchema   func(c *completer.Completer) error
+	matchMode     completer.MatchMode
+	noHelpCommand bool
+
+	listDescriptions bool
+	listQueryItems   int
+	autosuggest      bool
+	highlight        bool
+}
+
+//Option changes how Create makes an agent
+type Option func(o *options)
+
+//SchemaFiles loads the schema from files or directories on disk, instead of the backendConfigPath of Create
+func SchemaFiles(paths ...string) Option {
+	return func(o *options) {
+		o.setupSchema = func(c *completer.Completer) error {
+			return c.Setup(paths...)
+		}
+	}
+}
+
+//SchemaFS loads the schema from files or directories of fsys, e.g. an embed.FS
+func SchemaFS(fsys fs.FS, paths ...string) Option {
+	return func(o *options) {
+		o.setupSchema = func(c *completer.Completer) error {
+			return c.SetupFS(fsys, paths...)
+		}
+	}
+}
+
+//SchemaBytes loads the schema from data
+func SchemaBytes(data []byte, format completer.SchemaFormat) Option {
+	return func(o *options) {
+		o.setupSchema = func(c *completer.Completer) error {
+			return c.SetupBytes(data, format)
+		}
+	}
+}
+
+//SchemaReader loads the schema read from r
+func SchemaReader(r io.Reader, format completer.SchemaFormat) Option {
+	return func(o *options) {
+		o.setupSchema = func(c *completer.Completer) error {
+			return c.SetupReader(r, format)
+		}
+	}
+}
+
+//FuzzyMatch completes the keywords and values a field is a subsequence or a typo of, when it is a prefix of none,
+//e.g. "intf" to "interface"
+func FuzzyMatch() Option {
+	return func(o *options) {
+		o.matchMode = completer.MatchFuzzy
+	}
+}
+
+//NoHelpCommand leaves the built-in help command out of the schema
+func NoHelpCommand() Option {
+	return func(o *options) {
+		o.noHelpCommand = true
+	}
+}
+
+//ListDescriptions lists the completions of a field with their descriptions, one a line, instead of in columns
+func ListDescriptions() Option {
+	return func(o *options) {
+		o.listDescriptions = true
+	}
+}
+
+//ListQueryItems asks before listing more completions than n, 100 by default. A negative n never asks.
+func ListQueryItems(n int) Option {
+	return func(o *options) {
+		o.listQueryItems = n
+	}
+}
+
+//Autosuggest shows the rest of the line suggested by the history or the completions, dimmed after the cursor.
+//Right-arrow or Ctrl-F accepts it. It is not shown to the terminals told as dumb.
+func Autosuggest() Option {
+	return func(o *options) {
+		o.autosuggest = true
+	}
+}
+
+//Highlight colors the line as it is typed: the keywords bold, the valid values green, the invalid token red.
+//Alt-h toggles it in a session. It is not shown to the terminals told as dumb.
+func Highlight() Option {
+	return func(o *options) {
+		o.highlight = true
+	}
+}
@@ -5,6 +5,7 @@ import "fmt"
 type Input struct {
 	subMatches []string
 	raw        string
+	body       string
 	unitName   string
 }
 
@@ -26,6 +27,11 @@ func (c *Input) GetRaw() string {
 	return c.raw
 }
 
+//GetBody returns the multi-line block given after the command line (heredoc style input), or ""
+func (c *Input) GetBody() string {
+	return c.body
+}
+
 func (c *Input) GetName() string {
 	return c.unitName
 }
@@ -34,8 +40,9 @@ func (c *Input) GetUnitName() string {
 	return c.unitName
 }
 
-func createInput(raw string, subMatches []string, name string) (input Input) {
+func createInput(raw string, body string, subMatches []string, name string) (input Input) {
 	input.raw = raw
+	input.body = body
 	input.subMatches = subMatches
 	input.unitName = name
 	return
@@ -44,13 +44,35 @@ func (u *unit) Call(input Input, resultIO io.StringWriter) {
 	defaultHandlerCall(u, input, resultIO)
 }
 
+//Match tells if any registered unit takes the command
+func Match(command string) bool {
+	for _, unit := range commandSubscribs {
+		if unit.compiled.MatchString(command) {
+			return true
+		}
+	}
+	return false
+}
+
+//Call runs the handlers on the command the way Mux runs the ones of a unit the command matches, for a
+//command dispatched by other means than a pattern. The handlers get the name from Input.GetName.
+func Call(name string, command string, body string, handler DefaultHandler, progressHandler ProgressHandler, resultIO io.StringWriter) {
+	u := &unit{name: name, defaulthandler: handler, progressHandler: progressHandler}
+	u.Call(createInput(command, body, nil, name), resultIO)
+}
+
 func Mux(command string, resultIO io.StringWriter) (err error) {
+	return MuxWithBody(command, "", resultIO)
+}
+
+//MuxWithBody works like Mux, the body is the multi-line block given along with the command
+func MuxWithBody(command string, body string, resultIO io.StringWriter) (err error) {
 
 	handlerCnt := 0
 
 	for _, unit := range commandSubscribs {
 		if found := unit.compiled.FindStringSubmatch(command); found != nil {
-			unit.Call(createInput(command, found[1:], unit.name), resultIO)
+			unit.Call(createInput(command, body, found[1:], unit.name), resultIO)
 			handlerCnt++
 		}
 	}
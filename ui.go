@@ -2,6 +2,10 @@
 package cliui
 
 import (
+	"log"
+	"time"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
 	"github.com/ershixiongTQL/cli-ui/frontendtelnet"
 	"github.com/ershixiongTQL/cli-ui/interfaces"
 )
@@ -9,6 +13,8 @@ import (
 type Agent struct {
 	agentType interfaces.UI_AGENT_FE_TYPE
 	agent     interfaces.UIAgentInterface
+	backend   *uiBackend
+	stopWatch func()
 }
 
 func (agent *Agent) Start() error {
@@ -16,14 +22,50 @@ func (agent *Agent) Start() error {
 }
 
 func (agent *Agent) Stop() {
+	if agent.stopWatch != nil {
+		agent.stopWatch()
+		agent.stopWatch = nil
+	}
 	agent.agent.Stop()
 }
 
+//Reload loads the schema again. The sessions are not disturbed, and if the new schema
+//has errors the one in use is kept.
+func (agent *Agent) Reload() error {
+	return agent.backend.completer.Reload()
+}
+
+//WatchSchema reloads the schema whenever its files change, checking them every interval.
+//Reload errors are logged. The watching stops with the agent.
+func (agent *Agent) WatchSchema(interval time.Duration) {
+
+	if agent.stopWatch != nil {
+		agent.stopWatch()
+	}
+
+	agent.stopWatch = agent.backend.completer.Watch(interval, func(err error) {
+		if err != nil {
+			log.Println("schema reload failed, the schema in use is kept:", err.Error())
+		} else {
+			log.Println("schema reloaded")
+		}
+	})
+}
+
 func (agent *Agent) FrontEndType() interfaces.UI_AGENT_FE_TYPE {
 	return agent.agentType
 }
 
-func Create(frontType string, getPrompt func() string, getBanner func() string, backendConfigPath string, listenOn string) (agent *Agent) {
+//Create makes an agent. The schema is loaded from the file or directory backendConfigPath,
+//unless an option tells where it is, e.g. SchemaFS for an embed.FS.
+func Create(frontType string, getPrompt func() string, getBanner func() string, backendConfigPath string, listenOn string, opts ...Option) (agent *Agent) {
+
+	o := options{}
+	SchemaFiles(backendConfigPath)(&o)
+
+	for _, opt := range opts {
+		opt(&o)
+	}
 
 	agent = new(Agent)
 
@@ -31,20 +73,31 @@ func Create(frontType string, getPrompt func() string, getBanner func() string,
 
 		server := frontendtelnet.Server{}
 
-		backend := backendPrepare(backendConfigPath)
+		backend := backendPrepare(func(c *completer.Completer) error {
+			c.SetHelpCommand(!o.noHelpCommand)
+			return o.setupSchema(c)
+		})
 
 		if backend == nil {
 			return nil
 		}
 
+		backend.completer.SetMatchMode(o.matchMode)
+
 		server.Init(frontendtelnet.Config{
 			GetPrompt: getPrompt,
 			GetBanner: getBanner,
 			Backend:   backend,
 			ListenOn:  listenOn,
+
+			ListDescriptions: o.listDescriptions,
+			ListQueryItems:   o.listQueryItems,
+			Autosuggest:      o.autosuggest,
+			Highlight:        o.highlight,
 		})
 
 		agent.agent = &server
+		agent.backend = backend
 		agent.agentType = interfaces.UI_AGENT_FE_TYPE_TELNET
 
 	} else {
@@ -0,0 +1,52 @@
+package cliui
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/ershixiongTQL/cli-ui/completer"
+)
+
+//the agent goes on with the schema rewritten, and keeps it when the file is broken
+func TestWatchSchema(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "schema.json")
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [{"name": "a", "prefix": "a"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	be := backendPrepare(func(c *completer.Completer) error { return c.Setup(path) })
+	if be == nil {
+		t.Fatal("backend not prepared")
+	}
+
+	agent := &Agent{backend: be}
+	agent.WatchSchema(10 * time.Millisecond)
+	defer agent.stopWatch()
+
+	//wait until the line is taken as the schema tells
+	waitValid := func(line string) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); be.completer.Validate(line) != nil; time.Sleep(10 * time.Millisecond) {
+			if time.Now().After(deadline) {
+				t.Fatalf("%q is not taken", line)
+			}
+		}
+	}
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [{"name": "bb", "prefix": "bb"}]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	waitValid("bb")
+
+	if err := ioutil.WriteFile(path, []byte(`{"commands": [`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+
+	if err := be.completer.Validate("bb"); err != nil {
+		t.Errorf("the schema in use is not kept: %v", err)
+	}
+}